@@ -0,0 +1,271 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"kube-monkey/internal/pkg/chaos"
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/kubemonkey"
+	"kube-monkey/internal/pkg/kubernetes"
+	"kube-monkey/internal/pkg/notifications"
+	"kube-monkey/internal/pkg/plan"
+	"kube-monkey/internal/pkg/victims/factory"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Commands run instead of the daemon, e.g. kube-monkey validate
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) int
+}
+
+var commands = []command{
+	{
+		name:  "validate",
+		usage: "Check the config file without connecting to the cluster",
+		run:   validateCommand,
+	},
+	{
+		name:  "plan",
+		usage: "Print the schedule kube-monkey would generate now, without attacking",
+		run:   planCommand,
+	},
+	{
+		name:  "attack",
+		usage: "Attack a workload once, e.g. attack deployment/payments -n shop",
+		run:   attackCommand,
+	},
+}
+
+func commandsUsage() string {
+	var usage strings.Builder
+	for _, cmd := range commands {
+		fmt.Fprintf(&usage, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+	return usage.String()
+}
+
+// Runs the command with its arguments and returns its exit code
+func runCommand(name string, args []string) int {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(args)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\ncommands:\n%s", name, commandsUsage())
+	return 2
+}
+
+// Returns the flags of the command, with the path of the config file
+func commandFlags(name string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	configFile := flags.String("config", "", "Path of the config file. Defaults to config.toml in the working directory or /etc/kube-monkey")
+	return flags, configFile
+}
+
+// Loads and validates the config file, printing its invalid values
+func loadConfig(path string) bool {
+	if err := config.Load(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read config file: %v\n", err)
+		return false
+	}
+
+	errs := config.Validate()
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err.Message)
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "%s is invalid: %d errors\n", viper.ConfigFileUsed(), len(errs))
+		return false
+	}
+	return true
+}
+
+func validateCommand(args []string) int {
+	flags, configFile := commandFlags("validate")
+	_ = flags.Parse(args)
+
+	if !loadConfig(*configFile) {
+		return 1
+	}
+	fmt.Printf("%s is valid\n", viper.ConfigFileUsed())
+	return 0
+}
+
+func planCommand(args []string) int {
+	flags, configFile := commandFlags("plan")
+	output := flags.String("o", "table", "Output format, table or json")
+	_ = flags.Parse(args)
+
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown output format %q, expected table or json\n", *output)
+		return 2
+	}
+	if !loadConfig(*configFile) {
+		return 1
+	}
+
+	p, err := plan.New(kubernetes.NewClusterClientProviders())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to plan attacks: %v\n", err)
+		return 1
+	}
+
+	if *output == "json" {
+		err = p.WriteJSON(os.Stdout)
+	} else {
+		err = p.WriteTable(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print plan: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func attackCommand(args []string) int {
+	flags, configFile := commandFlags("attack")
+	namespace := flags.String("n", metav1.NamespaceDefault, "Namespace of the workload")
+	cluster := flags.String("cluster", "", "Name of the cluster of the workload, as listed in "+param.Clusters)
+	mode := flags.String("mode", "", "Kill mode of the attack, e.g. fixed or kill-all. Defaults to the kill mode labels of the workload")
+	value := flags.Int("value", 0, "Kill value of the attack, required by the kill modes other than kill-all")
+	var dryRun optionalBool
+	flags.Var(&dryRun, "dry-run", "Log the pods to kill instead of killing them. Defaults to "+param.DryRun+" of the config")
+	target, ok := parseInterspersed(flags, args)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "usage: kube-monkey attack [flags] <kind>/<name>\n")
+		flags.PrintDefaults()
+		return 2
+	}
+	kind, name, ok := strings.Cut(target, "/")
+	if !ok || kind == "" || name == "" {
+		fmt.Fprintf(os.Stderr, "Invalid target %q, expected <kind>/<name>, e.g. deployment/payments\n", target)
+		return 2
+	}
+	valueSet := false
+	flags.Visit(func(f *flag.Flag) {
+		valueSet = valueSet || f.Name == "value"
+	})
+	if valueSet && *mode == "" {
+		fmt.Fprintf(os.Stderr, "--value requires --mode, the kill value labels of the workload are used without it\n")
+		return 2
+	}
+
+	if !loadConfig(*configFile) {
+		return 1
+	}
+	if dryRun.value != nil {
+		config.SetFlag(param.DryRun, *dryRun.value)
+	}
+
+	clients := kubernetes.NewClusterClientProviders()
+	provider, ok := clients[*cluster]
+	if !ok {
+		if *cluster != "" {
+			fmt.Fprintf(os.Stderr, "Unknown cluster %q\n", *cluster)
+			return 1
+		}
+		provider = kubernetes.NewClientProvider()
+	}
+
+	victim, err := factory.FindVictim(*cluster, provider, factory.ResolveKind(kind), *namespace, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find %s: %v\n", target, err)
+		return 1
+	}
+
+	if config.DryRun() {
+		fmt.Printf("Attacking %s %s/%s in dry run mode\n", victim.Kind(), victim.Namespace(), victim.Name())
+	} else {
+		fmt.Printf("Attacking %s %s/%s\n", victim.Kind(), victim.Namespace(), victim.Name())
+	}
+
+	resultchan := make(chan *chaos.Result, 1)
+	chaos.NewWithKillMode(time.Now(), victim, *mode, *value).Execute(provider, resultchan)
+	result := <-resultchan
+
+	proxy := config.NotificationsProxy()
+	kubemonkey.ReportResult(result, notifications.CreateClient(&proxy))
+	return printResult(result)
+}
+
+// Prints the result of an attack and returns the exit code of the command,
+// which is non-zero unless the victim was attacked and recovered
+func printResult(result *chaos.Result) int {
+	switch {
+	case result.Skipped():
+		fmt.Printf("Skipped: %v\n", result.Error())
+		return 1
+	case result.Error() != nil:
+		fmt.Printf("Failed: %v\n", result.Error())
+		return 1
+	}
+
+	fmt.Printf("Succeeded: killed %d pods\n", result.PodsKilled())
+	recovery := result.Recovery()
+	if recovery == nil {
+		return 0
+	}
+	if !recovery.Recovered {
+		fmt.Printf("Not recovered after %s: %v\n", recovery.Duration.Round(time.Second), recovery.Err)
+		return 1
+	}
+	fmt.Printf("Recovered in %s\n", recovery.Duration.Round(time.Second))
+	return 0
+}
+
+// A boolean flag without a default, which is nil unless set, so that
+// it only overrides the config when given
+type optionalBool struct {
+	value *bool
+}
+
+func (b *optionalBool) String() string {
+	if b == nil || b.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b.value)
+}
+
+func (b *optionalBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	b.value = &v
+	return nil
+}
+
+func (b *optionalBool) IsBoolFlag() bool {
+	return true
+}
+
+// Parses the flags of a command taking one argument, which may precede
+// its flags, e.g. attack deployment/payments -n shop. Returns false
+// unless exactly one argument is given
+func parseInterspersed(flags *flag.FlagSet, args []string) (string, bool) {
+	var positional []string
+	for {
+		_ = flags.Parse(args)
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(positional) != 1 {
+		return "", false
+	}
+	return positional[0], true
+}
@@ -1,12 +1,24 @@
 package calendar
 
 import (
+	"fmt"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
 )
 
+const (
+	// Day is the length of a scheduling day. kube-monkey schedules
+	// terminations once per weekday, so a Day stands for one weekday
+	Day = 24 * time.Hour
+
+	// Week is a working week of five scheduling days
+	Week = 5 * Day
+)
+
 // Checks if specified Time is a weekday
 func isWeekday(t time.Time) bool {
 	switch t.Weekday() {
@@ -61,7 +73,66 @@ func RandomTimeInRange(startHour int, endHour int, loc *time.Location) time.Time
 
 	// Add the minute offset to the start of the range to get a random
 	// time within the range
-	year, month, date := time.Now().Date()
+	year, month, date := time.Now().In(loc).Date()
 	rangeStart := time.Date(year, month, date, startHour, 0, 0, 0, loc)
 	return rangeStart.Add(offsetDuration)
 }
+
+// RandomTimesInRange returns n random times within the range specified by startHour
+// and endHour, spread across the range by picking one time in each of n equal slots
+func RandomTimesInRange(startHour int, endHour int, n int, loc *time.Location) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+
+	year, month, date := time.Now().In(loc).Date()
+	rangeStart := time.Date(year, month, date, startHour, 0, 0, 0, loc)
+	slot := time.Duration(endHour-startHour) * time.Hour / time.Duration(n)
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	times := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		offset := time.Duration(i) * slot
+		if slot > 0 {
+			offset += time.Duration(r.Int63n(int64(slot)))
+		}
+		times = append(times, rangeStart.Add(offset).Truncate(time.Second))
+	}
+	return times
+}
+
+// ParseDuration parses a duration string as time.ParseDuration does, but
+// additionally accepts the units "d" (Day) and "w" (Week), e.g. "2w" or "1d12h"
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+
+	var total time.Duration
+	rest := s
+	for _, unit := range []struct {
+		suffix string
+		length time.Duration
+	}{{"w", Week}, {"d", Day}} {
+		i := strings.Index(rest, unit.suffix)
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid duration %q", s)
+		}
+		total += time.Duration(n) * unit.length
+		rest = rest[i+1:]
+	}
+
+	if rest == "" {
+		return total, nil
+	}
+
+	d, err := time.ParseDuration(rest)
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+	return total + d, nil
+}
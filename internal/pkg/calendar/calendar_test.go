@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIsWeekDay(t *testing.T) {
@@ -20,4 +21,53 @@ func TestIsWeekDay(t *testing.T) {
 	assert.False(t, isWeekday(monday.Add(time.Hour*24*6)))
 }
 
+func TestRandomTimesInRange(t *testing.T) {
+	loc := time.UTC
+	times := RandomTimesInRange(10, 16, 6, loc)
+
+	assert.Len(t, times, 6)
+	for i, killtime := range times {
+		// Each time falls into its own one hour slot
+		assert.Equal(t, 10+i, killtime.Hour(), "Expected time %d to be in slot starting at %d:00", i, 10+i)
+		assert.Equal(t, loc, killtime.Location())
+	}
+
+	assert.Empty(t, RandomTimesInRange(10, 16, 0, loc))
+
+	// The day is today's in the location, which may differ from the local one
+	for _, name := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago"} {
+		loc, err := time.LoadLocation(name)
+		require.NoError(t, err)
+		year, month, day := time.Now().In(loc).Date()
+		for _, killtime := range RandomTimesInRange(10, 16, 2, loc) {
+			assert.Equal(t, time.Date(year, month, day, 0, 0, 0, 0, loc), time.Date(killtime.Year(), killtime.Month(), killtime.Day(), 0, 0, 0, 0, loc))
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tcs := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"4h", 4 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"1d", Day},
+		{"2w", 2 * Week},
+		{"1d12h", Day + 12*time.Hour},
+		{"1w2d", Week + 2*Day},
+	}
+
+	for _, tc := range tcs {
+		d, err := ParseDuration(tc.value)
+		assert.NoError(t, err, tc.value)
+		assert.Equal(t, tc.expected, d, tc.value)
+	}
+
+	for _, value := range []string{"", "w", "xd", "2d1x", "string"} {
+		_, err := ParseDuration(value)
+		assert.Error(t, err, value)
+	}
+}
+
 // FIXME:  add more tests
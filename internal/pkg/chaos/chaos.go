@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/golang/glog"
 	"github.com/pkg/errors"
 
 	"kube-monkey/internal/pkg/config"
@@ -14,6 +15,10 @@ import (
 type Chaos struct {
 	killAt time.Time
 	victim victims.Victim
+
+	// Kill mode overriding the labels of the victim, if set
+	killType  string
+	killValue int
 }
 
 // New creates a new Chaos instance
@@ -25,6 +30,17 @@ func New(killtime time.Time, victim victims.Victim) *Chaos {
 	}
 }
 
+// NewWithKillMode creates a new Chaos instance that kills the victim with
+// the kill type and value instead of those of its labels, e.g. an experiment
+func NewWithKillMode(killtime time.Time, victim victims.Victim, killType string, killValue int) *Chaos {
+	return &Chaos{
+		killAt:    killtime,
+		victim:    victim,
+		killType:  killType,
+		killValue: killValue,
+	}
+}
+
 func (c *Chaos) Victim() victims.Victim {
 	return c.victim
 }
@@ -34,9 +50,9 @@ func (c *Chaos) KillAt() time.Time {
 }
 
 // Schedule the execution of Chaos
-func (c *Chaos) Schedule(resultchan chan<- *Result) {
+func (c *Chaos) Schedule(clients kubernetes.ClientProvider, resultchan chan<- *Result) {
 	time.Sleep(c.DurationToKillTime())
-	c.Execute(resultchan)
+	c.Execute(clients, resultchan)
 }
 
 // DurationToKillTime calculates the duration from now until Chaos.killAt
@@ -46,9 +62,9 @@ func (c *Chaos) DurationToKillTime() time.Duration {
 
 // Execute exposed function that calls the actual execution of the chaos, i.e. termination of pods
 // The result is sent back over the channel provided
-func (c *Chaos) Execute(resultchan chan<- *Result) {
-	// Create kubernetes clientset
-	clientset, dynamicClient, err := kubernetes.CreateClient()
+func (c *Chaos) Execute(clients kubernetes.ClientProvider, resultchan chan<- *Result) {
+	// Get the shared kubernetes clientset
+	clientset, dynamicClient, err := clients.Clients()
 	if err != nil {
 		resultchan <- c.NewResult(err)
 		return
@@ -56,23 +72,45 @@ func (c *Chaos) Execute(resultchan chan<- *Result) {
 
 	victimClient := victims.NewVictimClient(clientset, dynamicClient)
 
-	err = c.verifyExecution(victimClient)
+	// The checks before the attack share a single lookup of the victim
+	attackClient := victims.NewCachingVictimClient(victimClient)
+
+	err = c.verifyExecution(attackClient)
 	if err != nil {
 		resultchan <- c.NewResult(err)
 		return
 	}
 
-	err = c.terminate(victimClient)
-	if err != nil {
+	if err = c.recordState(attackClient); err != nil {
 		resultchan <- c.NewResult(err)
 		return
 	}
 
+	podsKilled, err := c.terminate(attackClient)
+	if err != nil {
+		// Pods may have been killed before the attack failed
+		result := c.NewResult(err)
+		result.podsKilled = podsKilled
+		resultchan <- result
+		return
+	}
+
+	// A failure to record the kill does not fail the attack
+	attackedAt := time.Now()
+	if err = c.recordKill(victimClient, attackedAt); err != nil {
+		glog.Warningf("Failed to record kill history for %s %s. Error: %v", c.Victim().Kind(), c.Victim().Name(), err)
+	}
+
 	// Send a success msg
-	resultchan <- c.NewResult(nil)
+	result := c.NewResult(nil)
+	result.podsKilled = podsKilled
+	result.attackedAt = attackedAt
+	result.recovery = c.verifyRecovery(victimClient, attackedAt)
+	resultchan <- result
 }
 
-// Verify if the victim has opted out since scheduling
+// Verify if the victim has opted out since scheduling, or is
+// rolling out or degraded at the time of termination
 func (c *Chaos) verifyExecution(client victims.VictimKubeClient) error {
 	// Is victim still enrolled in kube-monkey
 	enrolled, err := c.Victim().IsEnrolled(client)
@@ -81,65 +119,150 @@ func (c *Chaos) verifyExecution(client victims.VictimKubeClient) error {
 	}
 
 	if !enrolled {
-		return fmt.Errorf("%s %s is no longer enrolled in kube-monkey. Skipping", c.Victim().Kind(), c.Victim().Name())
+		return c.skip("is no longer enrolled in kube-monkey")
 	}
 
 	// Has the victim been blacklisted since scheduling?
 	if c.Victim().IsBlacklisted() {
-		return fmt.Errorf("%s %s is blacklisted. Skipping", c.Victim().Kind(), c.Victim().Name())
+		return c.skip("is blacklisted")
 	}
 
 	// Has the victim been removed from the whitelist since scheduling?
 	if !c.Victim().IsWhitelisted() {
-		return fmt.Errorf("%s %s is not whitelisted. Skipping", c.Victim().Kind(), c.Victim().Name())
+		return c.skip("is not whitelisted")
+	}
+
+	// Is the victim rolling out or degraded already?
+	if verifier, ok := c.Victim().(victims.StatusVerifier); ok {
+		reason, err := verifier.UnhealthyReason(client)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to check status of %s %s", c.Victim().Kind(), c.Victim().Name())
+		}
+		if reason != "" {
+			return c.skip(fmt.Sprintf("is not healthy (%s)", reason))
+		}
 	}
 
 	// Send back valid for termination
 	return nil
 }
 
+// Creates the error of a victim that is not attacked because of its state
+func (c *Chaos) skip(reason string) error {
+	return &victims.SkipError{Kind: c.Victim().Kind(), Name: c.Victim().Name(), Reason: reason}
+}
+
 // The termination type and value is processed here
-func (c *Chaos) terminate(client victims.VictimKubeClient) error {
-	killType, err := c.Victim().KillType(client)
+// Returns the number of pods killed, as reported by the victim
+func (c *Chaos) terminate(client victims.VictimKubeClient) (int, error) {
+	killType, err := c.getKillType(client)
 	if err != nil {
-		return errors.Wrapf(err, "Failed to check KillType label for %s %s", c.Victim().Kind(), c.Victim().Name())
+		return 0, err
+	}
+
+	// Kill modes specific to the victim do not require a kill-value
+	if handler, ok := c.Victim().(victims.KillModeHandler); ok && handler.HandlesKillMode(killType) {
+		return handler.Kill(client, killType, c.overriddenKillValue())
 	}
 
 	killValue, err := c.getKillValue(client)
 
 	// KillAll is the only kill type that does not require a kill-value
 	if killType != config.KillAllLabelValue && err != nil {
-		return err
+		return 0, err
 	}
 
 	// Validate killtype
+	var killNum int
 	switch killType {
 	case config.KillFixedLabelValue:
-		return c.Victim().DeleteRandomPods(client, killValue)
+		killNum = killValue
 	case config.KillAllLabelValue:
-		killNum, err := c.Victim().KillNumberForKillingAll(client)
-		if err != nil {
-			return err
-		}
-		return c.Victim().DeleteRandomPods(client, killNum)
+		killNum, err = c.Victim().KillNumberForKillingAll(client)
 	case config.KillRandomMaxLabelValue:
-		killNum, err := c.Victim().KillNumberForMaxPercentage(client, killValue)
-		if err != nil {
-			return err
-		}
-		return c.Victim().DeleteRandomPods(client, killNum)
+		killNum, err = c.Victim().KillNumberForMaxPercentage(client, killValue)
 	case config.KillFixedPercentageLabelValue:
-		killNum, err := c.Victim().KillNumberForFixedPercentage(client, killValue)
-		if err != nil {
-			return err
-		}
-		return c.Victim().DeleteRandomPods(client, killNum)
+		killNum, err = c.Victim().KillNumberForFixedPercentage(client, killValue)
 	default:
-		return fmt.Errorf("failed to recognize KillType label for %s %s", c.Victim().Kind(), c.Victim().Name())
+		return 0, fmt.Errorf("failed to recognize KillType label for %s %s", c.Victim().Kind(), c.Victim().Name())
+	}
+	if err != nil {
+		return 0, err
 	}
+
+	return c.Victim().DeleteRandomPods(client, killNum)
+}
+
+// Annotate the victim with the time of the attack, which is used
+// to enforce the cooldown of the victim when scheduling
+// Victims whose object was deleted by the kill are not annotated
+func (c *Chaos) recordKill(client victims.VictimKubeClient, killedAt time.Time) error {
+	annotations := c.Victim().RecordKill(killedAt)
+
+	if deleter, ok := c.Victim().(victims.ObjectDeleter); ok && deleter.DeletesObject() {
+		glog.V(3).Infof("Not recording kill for %s %s, its object was deleted", c.Victim().Kind(), c.Victim().Name())
+		return nil
+	}
+
+	if config.DryRun() {
+		glog.Infof("[DryRun Mode] Recorded kill at %s for %s/%s", killedAt.Format(time.RFC3339), c.Victim().Namespace(), c.Victim().Name())
+		return nil
+	}
+
+	return c.Victim().Annotate(client, annotations)
+}
+
+// Record the state of victims that verify their recovery against it
+func (c *Chaos) recordState(client victims.VictimKubeClient) error {
+	recorder, ok := c.Victim().(victims.StateRecorder)
+	if !ok || config.DryRun() {
+		return nil
+	}
+
+	if err := recorder.RecordState(client); err != nil {
+		return errors.Wrapf(err, "Failed to record state of %s %s", c.Victim().Kind(), c.Victim().Name())
+	}
+	return nil
+}
+
+// Wait for victims that can verify their recovery to recover from
+// the attack. Returns nil if the victim cannot verify its recovery
+func (c *Chaos) verifyRecovery(client victims.VictimKubeClient, attackedAt time.Time) *Recovery {
+	verifier, ok := c.Victim().(victims.RecoveryVerifier)
+	if !ok || config.DryRun() {
+		return nil
+	}
+
+	glog.V(3).Infof("Waiting for %s %s to recover", c.Victim().Kind(), c.Victim().Name())
+	err := verifier.VerifyRecovery(client, attackedAt, config.RecoveryTimeout())
+	return &Recovery{
+		Recovered: err == nil,
+		Duration:  time.Since(attackedAt),
+		Err:       err,
+	}
+}
+
+func (c *Chaos) getKillType(client victims.VictimKubeClient) (string, error) {
+	if c.killType != "" {
+		return c.killType, nil
+	}
+
+	killType, err := c.Victim().KillType(client)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to check KillType label for %s %s", c.Victim().Kind(), c.Victim().Name())
+	}
+
+	return killType, nil
 }
 
 func (c *Chaos) getKillValue(client victims.VictimKubeClient) (int, error) {
+	if c.killType != "" {
+		if c.killValue <= 0 {
+			return 0, fmt.Errorf("Invalid kill value %d for %s %s", c.killValue, c.Victim().Kind(), c.Victim().Name())
+		}
+		return c.killValue, nil
+	}
+
 	killValue, err := c.Victim().KillValue(client)
 	if err != nil {
 		return 0, errors.Wrapf(err, "Failed to check KillValue label for %s %s", c.Victim().Kind(), c.Victim().Name())
@@ -148,6 +271,15 @@ func (c *Chaos) getKillValue(client victims.VictimKubeClient) (int, error) {
 	return killValue, nil
 }
 
+// Returns the kill value overriding the label of the victim, or 0 for
+// the kill modes specific to the victim to read their label
+func (c *Chaos) overriddenKillValue() int {
+	if c.killType != "" {
+		return c.killValue
+	}
+	return 0
+}
+
 // NewResult creates a ChaosResult instance
 func (c *Chaos) NewResult(e error) *Result {
 	return &Result{
@@ -3,10 +3,14 @@ package chaos
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/kubernetes"
 	"kube-monkey/internal/pkg/victims"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
@@ -64,12 +68,57 @@ func (s *ChaosTestSuite) TestVerifyExecutionWhitelisted() {
 	s.NoError(err)
 }
 
+func (s *ChaosTestSuite) TestVerifyExecutionUnhealthy() {
+	v := &StatusVictimMock{VictimMock: NewVictimMock()}
+	s.chaos.victim = v
+	v.On("IsEnrolled", s.victimClient).Return(true, nil)
+	v.On("IsBlacklisted").Return(false)
+	v.On("IsWhitelisted").Return(true)
+	v.On("UnhealthyReason", s.victimClient).Return("rollout in progress: 1 of 3 replicas updated", nil)
+	err := s.chaos.verifyExecution(s.victimClient)
+	v.AssertExpectations(s.T())
+	s.EqualError(err, v.Kind()+" "+v.Name()+" is not healthy (rollout in progress: 1 of 3 replicas updated). Skipping")
+	s.True(s.chaos.NewResult(err).Skipped())
+}
+
+func (s *ChaosTestSuite) TestVerifyExecutionHealthy() {
+	v := &StatusVictimMock{VictimMock: NewVictimMock()}
+	s.chaos.victim = v
+	v.On("IsEnrolled", s.victimClient).Return(true, nil)
+	v.On("IsBlacklisted").Return(false)
+	v.On("IsWhitelisted").Return(true)
+	v.On("UnhealthyReason", s.victimClient).Return("", nil)
+	err := s.chaos.verifyExecution(s.victimClient)
+	v.AssertExpectations(s.T())
+	s.NoError(err)
+}
+
+func (s *ChaosTestSuite) TestExecuteUsesProvidedClients() {
+	v := s.chaos.victim.(*VictimMock)
+	v.On("IsEnrolled", mock.Anything).Return(false, nil)
+
+	resultchan := make(chan *Result, 1)
+	s.chaos.Execute(kubernetes.NewStaticClientProvider(s.client, nil), resultchan)
+
+	result := <-resultchan
+	v.AssertExpectations(s.T())
+	s.True(result.Skipped())
+	s.EqualError(result.Error(), v.Kind()+" "+v.Name()+" is no longer enrolled in kube-monkey. Skipping")
+}
+
+func (s *ChaosTestSuite) TestResultSkipped() {
+	s.True(s.chaos.NewResult(s.chaos.skip("is blacklisted")).Skipped())
+	s.False(s.chaos.NewResult(errors.New("failed")).Skipped())
+	s.False(s.chaos.NewResult(nil).Skipped())
+}
+
 func (s *ChaosTestSuite) TestTerminateKillTypeError() {
 	v := s.chaos.victim.(*VictimMock)
 	err := errors.New("KillType Error")
 	v.On("KillType", s.victimClient).Return("", err)
 
-	s.NotNil(s.chaos.terminate(s.victimClient))
+	_, err = s.chaos.terminate(s.victimClient)
+	s.NotNil(err)
 	v.AssertExpectations(s.T())
 }
 
@@ -78,7 +127,8 @@ func (s *ChaosTestSuite) TestTerminateKillValueError() {
 	errMsg := "KillValue Error"
 	v.On("KillType", s.victimClient).Return(config.KillFixedLabelValue, nil)
 	v.On("KillValue", s.victimClient).Return(0, errors.New(errMsg))
-	s.NotNil(s.chaos.terminate(s.victimClient))
+	_, err := s.chaos.terminate(s.victimClient)
+	s.NotNil(err)
 	v.AssertExpectations(s.T())
 }
 
@@ -87,8 +137,8 @@ func (s *ChaosTestSuite) TestTerminateKillFixed() {
 	killValue := 1
 	v.On("KillType", s.victimClient).Return(config.KillFixedLabelValue, nil)
 	v.On("KillValue", s.victimClient).Return(killValue, nil)
-	v.On("DeleteRandomPods", s.victimClient, killValue).Return(nil)
-	_ = s.chaos.terminate(s.victimClient)
+	v.On("DeleteRandomPods", s.victimClient, killValue).Return(killValue, nil)
+	_, _ = s.chaos.terminate(s.victimClient)
 	v.AssertExpectations(s.T())
 }
 
@@ -97,8 +147,8 @@ func (s *ChaosTestSuite) TestTerminateAllPods() {
 	v.On("KillType", s.victimClient).Return(config.KillAllLabelValue, nil)
 	v.On("KillValue", s.victimClient).Return(0, nil)
 	v.On("KillNumberForKillingAll", s.victimClient).Return(0, nil)
-	v.On("DeleteRandomPods", s.victimClient, 0).Return(nil)
-	_ = s.chaos.terminate(s.victimClient)
+	v.On("DeleteRandomPods", s.victimClient, 0).Return(0, nil)
+	_, _ = s.chaos.terminate(s.victimClient)
 	v.AssertExpectations(s.T())
 }
 
@@ -108,8 +158,8 @@ func (s *ChaosTestSuite) TestTerminateKillRandomMaxPercentage() {
 	v.On("KillType", s.victimClient).Return(config.KillRandomMaxLabelValue, nil)
 	v.On("KillValue", s.victimClient).Return(killValue, nil)
 	v.On("KillNumberForMaxPercentage", s.victimClient, mock.AnythingOfType("int")).Return(0, nil)
-	v.On("DeleteRandomPods", s.victimClient, 0).Return(nil)
-	_ = s.chaos.terminate(s.victimClient)
+	v.On("DeleteRandomPods", s.victimClient, 0).Return(0, nil)
+	_, _ = s.chaos.terminate(s.victimClient)
 	v.AssertExpectations(s.T())
 }
 
@@ -119,8 +169,8 @@ func (s *ChaosTestSuite) TestTerminateKillFixedPercentage() {
 	v.On("KillType", s.victimClient).Return(config.KillFixedPercentageLabelValue, nil)
 	v.On("KillValue", s.victimClient).Return(killValue, nil)
 	v.On("KillNumberForFixedPercentage", s.victimClient, mock.AnythingOfType("int")).Return(0, nil)
-	v.On("DeleteRandomPods", s.victimClient, 0).Return(nil)
-	_ = s.chaos.terminate(s.victimClient)
+	v.On("DeleteRandomPods", s.victimClient, 0).Return(0, nil)
+	_, _ = s.chaos.terminate(s.victimClient)
 	v.AssertExpectations(s.T())
 }
 
@@ -128,11 +178,68 @@ func (s *ChaosTestSuite) TestInvalidKillType() {
 	v := s.chaos.victim.(*VictimMock)
 	v.On("KillType", s.victimClient).Return("InvalidKillTypeHere", nil)
 	v.On("KillValue", s.victimClient).Return(0, nil)
-	err := s.chaos.terminate(s.victimClient)
+	_, err := s.chaos.terminate(s.victimClient)
 	v.AssertExpectations(s.T())
 	s.NotNil(err)
 }
 
+func (s *ChaosTestSuite) TestTerminateKillModeHandler() {
+	v := &KillModeVictimMock{VictimMock: NewVictimMock()}
+	s.chaos.victim = v
+	v.On("KillType", s.victimClient).Return("switchover", nil)
+	v.On("HandlesKillMode", "switchover").Return(true)
+	v.On("Kill", s.victimClient, "switchover", 0).Return(0, nil)
+	podsKilled, err := s.chaos.terminate(s.victimClient)
+	v.AssertExpectations(s.T())
+	v.AssertNotCalled(s.T(), "KillValue", s.victimClient)
+	s.NoError(err)
+	s.Equal(0, podsKilled)
+}
+
+func (s *ChaosTestSuite) TestTerminateKillModeHandlerOverriddenValue() {
+	v := &KillModeVictimMock{VictimMock: NewVictimMock()}
+	s.chaos = NewWithKillMode(s.chaos.KillAt(), v, "random-nodes", 3)
+	v.On("HandlesKillMode", "random-nodes").Return(true)
+	v.On("Kill", s.victimClient, "random-nodes", 3).Return(3, nil)
+	podsKilled, err := s.chaos.terminate(s.victimClient)
+	v.AssertExpectations(s.T())
+	v.AssertNotCalled(s.T(), "KillType", s.victimClient)
+	v.AssertNotCalled(s.T(), "KillValue", s.victimClient)
+	s.NoError(err)
+	s.Equal(3, podsKilled)
+}
+
+func (s *ChaosTestSuite) TestTerminateKillModeNotHandled() {
+	v := &KillModeVictimMock{VictimMock: NewVictimMock()}
+	s.chaos.victim = v
+	v.On("KillType", s.victimClient).Return(config.KillFixedLabelValue, nil)
+	v.On("HandlesKillMode", config.KillFixedLabelValue).Return(false)
+	v.On("KillValue", s.victimClient).Return(1, nil)
+	v.On("DeleteRandomPods", s.victimClient, 1).Return(1, nil)
+	_, _ = s.chaos.terminate(s.victimClient)
+	v.AssertExpectations(s.T())
+}
+
+func (s *ChaosTestSuite) TestTerminateKillModeOverride() {
+	v := s.chaos.victim.(*VictimMock)
+	s.chaos = NewWithKillMode(s.chaos.KillAt(), v, config.KillFixedPercentageLabelValue, 50)
+	v.On("KillNumberForFixedPercentage", s.victimClient, 50).Return(2, nil)
+	v.On("DeleteRandomPods", s.victimClient, 2).Return(1, nil)
+	podsKilled, err := s.chaos.terminate(s.victimClient)
+	v.AssertExpectations(s.T())
+	v.AssertNotCalled(s.T(), "KillType", s.victimClient)
+	v.AssertNotCalled(s.T(), "KillValue", s.victimClient)
+	s.NoError(err)
+	s.Equal(1, podsKilled, "Expected the number of pods actually deleted")
+}
+
+func (s *ChaosTestSuite) TestTerminateKillModeOverrideInvalidValue() {
+	v := s.chaos.victim.(*VictimMock)
+	s.chaos = NewWithKillMode(s.chaos.KillAt(), v, config.KillFixedLabelValue, 0)
+	_, err := s.chaos.terminate(s.victimClient)
+	s.EqualError(err, "Invalid kill value 0 for "+v.Kind()+" "+v.Name())
+}
+
 func (s *ChaosTestSuite) TestGetKillValue() {
 	v := s.chaos.victim.(*VictimMock)
 	killValue := 5
@@ -149,6 +256,68 @@ func (s *ChaosTestSuite) TestGetKillValueReturnsError() {
 	s.NotNil(err)
 }
 
+func (s *ChaosTestSuite) TestRecordKill() {
+	viper.Set(param.DryRun, false)
+	defer viper.Set(param.DryRun, true)
+
+	v := s.chaos.victim.(*VictimMock)
+	killedAt := time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC)
+	v.On("Annotate", s.victimClient, map[string]string{
+		config.LastKilledAnnotationKey:  "2024-01-02T10:30:00Z",
+		config.KillHistoryAnnotationKey: "2024-01-02T10:30:00Z",
+	}).Return(nil)
+
+	s.NoError(s.chaos.recordKill(s.victimClient, killedAt))
+	v.AssertExpectations(s.T())
+
+	lastKilled, ok := v.LastKilled()
+	s.True(ok)
+	s.Equal(killedAt, lastKilled)
+}
+
+func (s *ChaosTestSuite) TestRecordKillDryRun() {
+	viper.Set(param.DryRun, true)
+
+	v := s.chaos.victim.(*VictimMock)
+	s.NoError(s.chaos.recordKill(s.victimClient, time.Now()))
+	v.AssertNotCalled(s.T(), "Annotate", mock.Anything, mock.Anything)
+}
+
+func (s *ChaosTestSuite) TestRecordKillDeletedObject() {
+	viper.Set(param.DryRun, false)
+	defer viper.Set(param.DryRun, true)
+
+	v := &ObjectDeleterVictimMock{VictimMock: NewVictimMock()}
+	s.chaos.victim = v
+	v.On("DeletesObject").Return(true)
+
+	s.NoError(s.chaos.recordKill(s.victimClient, time.Now()))
+	v.AssertExpectations(s.T())
+	v.AssertNotCalled(s.T(), "Annotate", mock.Anything, mock.Anything)
+}
+
+func (s *ChaosTestSuite) TestRecordState() {
+	viper.Set(param.DryRun, false)
+	defer viper.Set(param.DryRun, true)
+
+	v := &StateVictimMock{VictimMock: NewVictimMock()}
+	s.chaos.victim = v
+	v.On("RecordState", s.victimClient).Return(errors.New("not found"))
+
+	s.EqualError(s.chaos.recordState(s.victimClient), "Failed to record state of "+KIND+" "+NAME+": not found")
+	v.AssertExpectations(s.T())
+}
+
+func (s *ChaosTestSuite) TestRecordStateDryRun() {
+	viper.Set(param.DryRun, true)
+
+	v := &StateVictimMock{VictimMock: NewVictimMock()}
+	s.chaos.victim = v
+
+	s.NoError(s.chaos.recordState(s.victimClient))
+	v.AssertNotCalled(s.T(), "RecordState", mock.Anything)
+}
+
 // Disabling test
 // See https://github.com/asobti/kube-monkey/issues/126
 //func (s *ChaosTestSuite) TestDurationToKillTime() {
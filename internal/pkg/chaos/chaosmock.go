@@ -3,6 +3,7 @@ package chaos
 import (
 	"time"
 
+	"kube-monkey/internal/pkg/calendar"
 	"kube-monkey/internal/pkg/victims"
 
 	"github.com/stretchr/testify/mock"
@@ -36,14 +37,19 @@ func (vm *VictimMock) KillValue(client victims.VictimKubeClient) (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (vm *VictimMock) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	args := vm.Called(client, annotations)
+	return args.Error(0)
+}
+
 func (vm *VictimMock) DeleteRandomPod(client victims.VictimKubeClient) error {
 	args := vm.Called(client)
 	return args.Error(0)
 }
 
-func (vm *VictimMock) DeleteRandomPods(client victims.VictimKubeClient, killValue int) error {
+func (vm *VictimMock) DeleteRandomPods(client victims.VictimKubeClient, killValue int) (int, error) {
 	args := vm.Called(client, killValue)
-	return args.Error(0)
+	return args.Int(0), args.Error(1)
 }
 
 func (vm *VictimMock) KillNumberForKillingAll(client victims.VictimKubeClient) (int, error) {
@@ -71,8 +77,53 @@ func (vm *VictimMock) IsWhitelisted() bool {
 	return args.Bool(0)
 }
 
+// KillModeVictimMock is a VictimMock with kill modes of its own
+type KillModeVictimMock struct {
+	*VictimMock
+}
+
+func (vm *KillModeVictimMock) HandlesKillMode(killType string) bool {
+	args := vm.Called(killType)
+	return args.Bool(0)
+}
+
+func (vm *KillModeVictimMock) Kill(client victims.VictimKubeClient, killType string, killValue int) (int, error) {
+	args := vm.Called(client, killType, killValue)
+	return args.Int(0), args.Error(1)
+}
+
+// StatusVictimMock is a VictimMock reporting its status
+type StatusVictimMock struct {
+	*VictimMock
+}
+
+func (vm *StatusVictimMock) UnhealthyReason(client victims.VictimKubeClient) (string, error) {
+	args := vm.Called(client)
+	return args.String(0), args.Error(1)
+}
+
+// StateVictimMock is a VictimMock recording its state before an attack
+type StateVictimMock struct {
+	*VictimMock
+}
+
+func (vm *StateVictimMock) RecordState(client victims.VictimKubeClient) error {
+	args := vm.Called(client)
+	return args.Error(0)
+}
+
+// ObjectDeleterVictimMock is a VictimMock whose kills delete its object
+type ObjectDeleterVictimMock struct {
+	*VictimMock
+}
+
+func (vm *ObjectDeleterVictimMock) DeletesObject() bool {
+	args := vm.Called()
+	return args.Bool(0)
+}
+
 func NewVictimMock() *VictimMock {
-	v := victims.New(KIND, NAME, NAMESPACE, IDENTIFIER, 1)
+	v := victims.New(KIND, NAME, NAMESPACE, IDENTIFIER, calendar.Day)
 	return &VictimMock{
 		VictimBase: v,
 	}
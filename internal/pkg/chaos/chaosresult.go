@@ -1,12 +1,25 @@
 package chaos
 
 import (
+	"errors"
+	"time"
+
 	"kube-monkey/internal/pkg/victims"
 )
 
 type Result struct {
-	chaos *Chaos
-	err   error
+	chaos      *Chaos
+	err        error
+	podsKilled int
+	attackedAt time.Time
+	recovery   *Recovery
+}
+
+// Recovery describes whether a victim recovered after an attack
+type Recovery struct {
+	Recovered bool
+	Duration  time.Duration
+	Err       error
 }
 
 func (r *Result) Victim() victims.Victim {
@@ -17,6 +30,30 @@ func (r *Result) Error() error {
 	return r.err
 }
 
+// Skipped checks if the victim was not attacked because of its state
+func (r *Result) Skipped() bool {
+	var skipErr *victims.SkipError
+	return errors.As(r.err, &skipErr)
+}
+
+// PodsKilled returns the number of pods actually killed by the attack,
+// including those killed before it failed
+func (r *Result) PodsKilled() int {
+	return r.podsKilled
+}
+
+// AttackedAt returns the time of the attack, which is zero if the
+// victim was not attacked
+func (r *Result) AttackedAt() time.Time {
+	return r.attackedAt
+}
+
+// Recovery returns the recovery of the victim after the attack, or nil
+// if the victim does not verify its recovery or the attack failed
+func (r *Result) Recovery() *Recovery {
+	return r.recovery
+}
+
 // NewResult creates a new Result instance
 func NewResult(chaos *Chaos, err error) *Result {
 	return &Result{
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config/param"
+)
+
+// ClusterTarget describes a cluster targeted by kube-monkey
+type ClusterTarget struct {
+	// Name tags the schedule, results and notifications of the cluster
+	Name string `mapstructure:"name"`
+
+	// Kubeconfig is the path of the kubeconfig file of the cluster
+	// Defaults to the files of the KUBECONFIG environment variable
+	// or ~/.kube/config
+	Kubeconfig string `mapstructure:"kubeconfig"`
+
+	// Context is the context of the cluster in the kubeconfig file
+	// Defaults to the current context
+	Context string `mapstructure:"context"`
+
+	// WhitelistedNamespaces and BlacklistedNamespaces override
+	// config.WhitelistedNamespaces and config.BlacklistedNamespaces
+	// in the cluster when set
+	WhitelistedNamespaces []string `mapstructure:"whitelisted_namespaces"`
+	BlacklistedNamespaces []string `mapstructure:"blacklisted_namespaces"`
+}
+
+// Clusters returns the clusters targeted by kube-monkey, if configured
+func Clusters() []ClusterTarget {
+	return read(settings.clusters)
+}
+
+// ClusterNamed returns the cluster target with the name, and false if
+// there is none, e.g. for the unnamed cluster of a single cluster setup
+func ClusterNamed(name string) (ClusterTarget, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return live().clusterNamed(name)
+}
+
+func (s settings) clusters() []ClusterTarget {
+	var clusters []ClusterTarget
+	err := s.UnmarshalKey(param.Clusters, &clusters)
+	if err != nil {
+		glog.Errorf("Failed to parse %s %v", param.Clusters, err)
+	}
+	return clusters
+}
+
+func (s settings) clusterNamed(name string) (ClusterTarget, bool) {
+	if name == "" {
+		return ClusterTarget{}, false
+	}
+	for _, cluster := range s.clusters() {
+		if cluster.Name == name {
+			return cluster, true
+		}
+	}
+	return ClusterTarget{}, false
+}
+
+func (s settings) blacklistedNamespacesIn(cluster string) []string {
+	if target, ok := s.clusterNamed(cluster); ok && target.BlacklistedNamespaces != nil {
+		return target.BlacklistedNamespaces
+	}
+	return s.GetStringSlice(param.BlacklistedNamespaces)
+}
+
+func (s settings) whitelistedNamespacesIn(cluster string) []string {
+	if target, ok := s.clusterNamed(cluster); ok && target.WhitelistedNamespaces != nil {
+		return target.WhitelistedNamespaces
+	}
+	return s.GetStringSlice(param.WhitelistedNamespaces)
+}
+
+// KubeMonkeyID returns the ID tagging the schedule, results and
+// notifications of the cluster: the name of the cluster target if
+// set, and the KUBE_MONKEY_ID environment variable otherwise
+func KubeMonkeyID(cluster string) string {
+	if cluster != "" {
+		return cluster
+	}
+	return os.Getenv("KUBE_MONKEY_ID")
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"kube-monkey/internal/pkg/config/param"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func setClusters(clusters ...map[string]interface{}) {
+	viper.Set(param.Clusters, clusters)
+}
+
+func TestClusters(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+
+	assert.Empty(t, Clusters())
+
+	setClusters(
+		map[string]interface{}{"name": "staging", "kubeconfig": "/etc/kube-monkey/clusters/staging", "context": "staging", "whitelisted_namespaces": []string{"team-*"}},
+		map[string]interface{}{"name": "production", "context": "production"},
+	)
+
+	clusters := Clusters()
+	assert.Len(t, clusters, 2)
+	assert.Equal(t, ClusterTarget{
+		Name:                  "staging",
+		Kubeconfig:            "/etc/kube-monkey/clusters/staging",
+		Context:               "staging",
+		WhitelistedNamespaces: []string{"team-*"},
+	}, clusters[0])
+
+	cluster, ok := ClusterNamed("production")
+	assert.True(t, ok)
+	assert.Equal(t, "production", cluster.Context)
+
+	_, ok = ClusterNamed("")
+	assert.False(t, ok)
+	_, ok = ClusterNamed("unknown")
+	assert.False(t, ok)
+}
+
+func TestNamespacesIn(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+
+	setClusters(
+		map[string]interface{}{"name": "staging", "whitelisted_namespaces": []string{"team-a"}, "blacklisted_namespaces": []string{""}},
+		map[string]interface{}{"name": "production"},
+	)
+
+	assert.Equal(t, []string{"team-a"}, WhitelistedNamespacesIn("staging").List())
+	assert.False(t, BlacklistEnabledIn("staging"), "Expected the cluster to disable the blacklist")
+
+	assert.Equal(t, []string{metav1.NamespaceAll}, WhitelistedNamespacesIn("production").List(), "Expected the namespaces to default to the global ones")
+	assert.True(t, BlacklistEnabledIn("production"))
+	assert.True(t, BlacklistEnabled())
+}
+
+func TestKubeMonkeyID(t *testing.T) {
+	envname := "KUBE_MONKEY_ID"
+	defer os.Setenv(envname, os.Getenv(envname))
+	os.Setenv(envname, "TestingID")
+
+	assert.Equal(t, "TestingID", KubeMonkeyID(""))
+	assert.Equal(t, "staging", KubeMonkeyID("staging"), "Expected the cluster name in place of KUBE_MONKEY_ID")
+}
+
+func TestClusterErrors(t *testing.T) {
+	blacklisted := []string{metav1.NamespaceSystem}
+
+	assert.Empty(t, clusterErrors([]ClusterTarget{{Name: "staging"}, {Name: "production"}}, nil, blacklisted))
+	assert.Equal(t, []string{param.Clusters + " entries require a name"}, clusterErrors([]ClusterTarget{{Context: "staging"}}, nil, blacklisted))
+	assert.Equal(t, []string{"cluster staging is defined more than once"}, clusterErrors([]ClusterTarget{{Name: "staging"}, {Name: "staging"}}, nil, blacklisted))
+
+	problems := clusterErrors([]ClusterTarget{
+		{Context: "staging"},
+		{Name: "staging", BlacklistedNamespaces: []string{"/kube-(/"}},
+		{Name: "production", WhitelistedNamespaces: []string{"/kube-(/"}},
+	}, nil, blacklisted)
+	if assert.Len(t, problems, 3, "Expected a problem for every cluster") {
+		assert.Contains(t, problems[1], "blacklisted namespaces of cluster staging")
+		assert.Contains(t, problems[2], "whitelisted namespaces of cluster production")
+	}
+
+	assert.Equal(t, []string{"whitelisted namespaces of cluster staging: app are also blacklisted"},
+		clusterErrors([]ClusterTarget{{Name: "staging", WhitelistedNamespaces: []string{"app", "web"}, BlacklistedNamespaces: []string{"app"}}}, nil, blacklisted))
+	assert.Equal(t, []string{"whitelisted namespaces of cluster staging: " + metav1.NamespaceSystem + " are also blacklisted"},
+		clusterErrors([]ClusterTarget{{Name: "staging", WhitelistedNamespaces: []string{metav1.NamespaceSystem}}}, nil, blacklisted), "Expected the global blacklist to apply to the cluster")
+	assert.Empty(t, clusterErrors([]ClusterTarget{{Name: "staging", WhitelistedNamespaces: []string{metav1.NamespaceSystem}, BlacklistedNamespaces: []string{}}}, nil, blacklisted))
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"strings"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"kube-monkey/internal/pkg/config/param"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -33,6 +35,30 @@ const (
 	KillFixedPercentageLabelValue = "fixed-percent"
 	KillFixedLabelValue           = "fixed"
 	KillAllLabelValue             = "kill-all"
+	CooldownLabelKey              = "kube-monkey/cooldown-hours"
+	SkipUnhealthyLabelKey         = "kube-monkey/skip-unhealthy"
+
+	// Set on individual pods to exclude them from termination
+	// without unenrolling their k8s app, optionally until a time
+	ProtectKey                = "kube-monkey/protect"
+	ProtectValue              = "true"
+	ProtectUntilAnnotationKey = "kube-monkey/protect-until"
+
+	// Annotations written by kube-monkey on a victim after
+	// its pods have been terminated
+	LastKilledAnnotationKey  = "kube-monkey/last-killed"
+	KillHistoryAnnotationKey = "kube-monkey/kill-history"
+	KillHistoryLength        = 5
+
+	// Policies selecting the pods of a victim that can be terminated
+	PodPolicyRunning        = "running"
+	PodPolicyNotTerminating = "not-terminating"
+	PodPolicyReady          = "ready"
+
+	// Ways of finding the pods of a custom resource victim
+	PodSelectionIdentifier = "identifier"
+	PodSelectionSelector   = "selector"
+	PodSelectionOwner      = "owner"
 )
 
 type Receiver struct {
@@ -50,152 +76,337 @@ func NewReceiver(endpoint string, message string, headers []string) Receiver {
 	}
 }
 
-func SetDefaults() {
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
+// CustomResource describes a kind of custom resource that can be
+// enrolled as a victim, and how to find the pods that belong to it
+type CustomResource struct {
+	Group    string `mapstructure:"group"`
+	Version  string `mapstructure:"version"`
+	Resource string `mapstructure:"resource"`
+
+	// PodSelection is one of PodSelectionIdentifier, PodSelectionSelector
+	// or PodSelectionOwner. Defaults to PodSelectionIdentifier
+	PodSelection string `mapstructure:"pod_selection"`
+
+	// SelectorPath is the dot separated path to the label selector
+	// of the pods in the resource, e.g. "spec.selector"
+	// Only used with PodSelectionSelector
+	SelectorPath string `mapstructure:"selector_path"`
+}
 
-	viper.SetDefault(param.DryRun, true)
-	viper.SetDefault(param.Timezone, "America/Los_Angeles")
-	viper.SetDefault(param.RunHour, 8)
-	viper.SetDefault(param.StartHour, 10)
-	viper.SetDefault(param.EndHour, 16)
-	viper.SetDefault(param.GracePeriodSec, 5)
-	viper.SetDefault(param.BlacklistedNamespaces, []string{metav1.NamespaceSystem})
-	viper.SetDefault(param.WhitelistedNamespaces, []string{metav1.NamespaceAll})
+// GroupVersionResource returns the GroupVersionResource of the custom resource
+func (c CustomResource) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: c.Group, Version: c.Version, Resource: c.Resource}
+}
 
-	viper.SetDefault(param.DebugEnabled, false)
-	viper.SetDefault(param.DebugScheduleDelay, 30)
-	viper.SetDefault(param.DebugForceShouldKill, false)
-	viper.SetDefault(param.DebugScheduleImmediateKill, false)
+func SetDefaults() {
+	setDefaults(viper.GetViper())
+}
 
-	viper.SetDefault(param.NotificationsEnabled, false)
-	viper.SetDefault(param.NotificationsProxy, nil)
-	viper.SetDefault(param.NotificationsReportSchedule, false)
-	viper.SetDefault(param.NotificationsAttacks, Receiver{})
+// Sets the defaults of the params, and reads them from the environment
+func setDefaults(v *viper.Viper) {
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	v.SetDefault(param.DryRun, true)
+	v.SetDefault(param.Timezone, "America/Los_Angeles")
+	v.SetDefault(param.RunHour, 8)
+	v.SetDefault(param.StartHour, 10)
+	v.SetDefault(param.EndHour, 16)
+	v.SetDefault(param.GracePeriodSec, 5)
+	v.SetDefault(param.CooldownHours, 0)
+	v.SetDefault(param.RecoveryTimeoutSec, 600)
+	v.SetDefault(param.PodSelectionPolicy, PodPolicyReady)
+	v.SetDefault(param.ClusterWideDiscovery, true)
+	v.SetDefault(param.BlacklistedNamespaces, []string{metav1.NamespaceSystem})
+	v.SetDefault(param.WhitelistedNamespaces, []string{metav1.NamespaceAll})
+	v.SetDefault(param.BlacklistedNamespaceSelector, "")
+	v.SetDefault(param.WhitelistedNamespaceSelector, "")
+	v.SetDefault(param.DisabledVictimKinds, []string{})
+	v.SetDefault(param.CustomResources, []CustomResource{})
+	v.SetDefault(param.ChaosPoliciesEnabled, false)
+	v.SetDefault(param.ChaosExperimentsEnabled, false)
+	v.SetDefault(param.StatusAddress, "")
+
+	v.SetDefault(param.ClientQPS, 5)
+	v.SetDefault(param.ClientBurst, 10)
+	v.SetDefault(param.ClientUserAgent, "kube-monkey")
+	v.SetDefault(param.Kubeconfig, "")
+	v.SetDefault(param.KubeContext, "")
+	v.SetDefault(param.ImpersonateUser, "")
+	v.SetDefault(param.ImpersonateGroups, []string{})
+	v.SetDefault(param.Clusters, []ClusterTarget{})
+
+	v.SetDefault(param.DebugEnabled, false)
+	v.SetDefault(param.DebugScheduleDelay, 30)
+	v.SetDefault(param.DebugForceShouldKill, false)
+	v.SetDefault(param.DebugScheduleImmediateKill, false)
+
+	v.SetDefault(param.NotificationsEnabled, false)
+	v.SetDefault(param.NotificationsProxy, nil)
+	v.SetDefault(param.NotificationsReportSchedule, false)
+	v.SetDefault(param.NotificationsAttacks, Receiver{})
 }
 
+// Watches the config file with a viper of its own, as viper reads
+// the changed file into the watching viper before notifying it
 func setupWatch() {
-	viper.WatchConfig()
-	viper.OnConfigChange(func(e fsnotify.Event) {
+	watcher := viper.New()
+	watcher.SetConfigType(configtype)
+	watcher.SetConfigFile(viper.ConfigFileUsed())
+	watcher.WatchConfig()
+	watcher.OnConfigChange(func(e fsnotify.Event) {
 		glog.V(4).Info("Config change detected")
-		if err := ValidateConfigs(); err != nil {
-			panic(err)
+		if err := reloadFile(watcher.ConfigFileUsed()); err != nil {
+			glog.Errorf("Rejected config change, keeping the previous config. Error: %v", err)
+			return
 		}
-		glog.V(4).Info("Successfully reloaded configs")
+		glog.V(4).Infof("Successfully reloaded configs, generation %d", Status().Generation)
 	})
 }
 
-func Init() error {
+// Load reads the config file at path over the defaults, without
+// validating it. If path is empty, the config file is looked up in the
+// working directory and then in /etc/kube-monkey
+func Load(path string) error {
 	SetDefaults()
-	viper.AddConfigPath(".")
-	viper.AddConfigPath(configpath)
 	viper.SetConfigType(configtype)
-	viper.SetConfigName(configname)
+	if path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.AddConfigPath(".")
+		viper.AddConfigPath(configpath)
+		viper.SetConfigName(configname)
+	}
 
 	if err := viper.ReadInConfig(); err != nil {
 		return err
 	}
 
-	if err := ValidateConfigs(); err != nil {
-		glog.Errorf("Failed to validate %v", err)
+	// Kept to build the config with its overrides
+	data, err := os.ReadFile(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	file = data
+	return nil
+}
+
+func Init() error {
+	if err := Load(""); err != nil {
 		return err
 	}
+
+	if errs := Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			glog.Errorf("Failed to validate %s: %s", err.Key, err.Message)
+		}
+		return errs
+	}
 	glog.V(4).Info("Successfully validated configs")
+
+	acceptConfig()
 	setupWatch()
 	return nil
 }
 
 func DryRun() bool {
-	return viper.GetBool(param.DryRun)
+	return read(settings.dryRun)
 }
 
 func Timezone() *time.Location {
-	tz := viper.GetString(param.Timezone)
-	location, err := time.LoadLocation(tz)
-	if err != nil {
-		glog.Fatal(err.Error())
-	}
-	return location
+	return read(settings.timezone)
 }
 
 func RunHour() int {
-	return viper.GetInt(param.RunHour)
+	return read(settings.runHour)
 }
 
 func StartHour() int {
-	return viper.GetInt(param.StartHour)
+	return read(settings.startHour)
 }
 
 func EndHour() int {
-	return viper.GetInt(param.EndHour)
+	return read(settings.endHour)
 }
 
 func GracePeriodSeconds() *int64 {
-	gpInt64 := viper.GetInt64(param.GracePeriodSec)
-	return &gpInt64
+	return read(settings.gracePeriodSeconds)
+}
+
+func Cooldown() time.Duration {
+	return read(settings.cooldown)
+}
+
+func RecoveryTimeout() time.Duration {
+	return read(settings.recoveryTimeout)
+}
+
+func PodSelectionPolicy() string {
+	return read(settings.podSelectionPolicy)
+}
+
+func ClusterWideDiscovery() bool {
+	return read(settings.clusterWideDiscovery)
 }
 
 func BlacklistedNamespaces() sets.String {
-	// Return as set for O(1) membership checks
-	namespaces := viper.GetStringSlice(param.BlacklistedNamespaces)
-	return sets.NewString(namespaces...)
+	return BlacklistedNamespacesIn("")
 }
 
 func WhitelistedNamespaces() sets.String {
+	return WhitelistedNamespacesIn("")
+}
+
+// BlacklistedNamespacesIn returns the blacklisted namespaces of the
+// cluster target, defaulting to config.BlacklistedNamespaces
+func BlacklistedNamespacesIn(cluster string) sets.String {
 	// Return as set for O(1) membership checks
-	namespaces := viper.GetStringSlice(param.WhitelistedNamespaces)
-	return sets.NewString(namespaces...)
+	return sets.NewString(read(func(s settings) []string { return s.blacklistedNamespacesIn(cluster) })...)
+}
+
+// WhitelistedNamespacesIn returns the whitelisted namespaces of the
+// cluster target, defaulting to config.WhitelistedNamespaces
+func WhitelistedNamespacesIn(cluster string) sets.String {
+	// Return as set for O(1) membership checks
+	return sets.NewString(read(func(s settings) []string { return s.whitelistedNamespacesIn(cluster) })...)
+}
+
+func BlacklistedNamespaceSelector() string {
+	return read(settings.blacklistedNamespaceSelector)
+}
+
+func WhitelistedNamespaceSelector() string {
+	return read(settings.whitelistedNamespaceSelector)
 }
 
 func BlacklistEnabled() bool {
-	return !BlacklistedNamespaces().Equal(sets.NewString(metav1.NamespaceNone))
+	return BlacklistEnabledIn("")
 }
 
 func WhitelistEnabled() bool {
-	return !WhitelistedNamespaces().Equal(sets.NewString(metav1.NamespaceAll))
+	return WhitelistEnabledIn("")
+}
+
+func BlacklistEnabledIn(cluster string) bool {
+	return read(func(s settings) bool { return s.blacklistEnabledIn(cluster) })
+}
+
+func WhitelistEnabledIn(cluster string) bool {
+	return read(func(s settings) bool { return s.whitelistEnabledIn(cluster) })
+}
+
+// NamespaceBlacklist returns the matcher of the blacklisted namespaces
+func NamespaceBlacklist() (*NamespaceMatcher, error) {
+	return NamespaceBlacklistIn("")
+}
+
+// NamespaceWhitelist returns the matcher of the whitelisted namespaces
+func NamespaceWhitelist() (*NamespaceMatcher, error) {
+	return NamespaceWhitelistIn("")
+}
+
+// NamespaceBlacklistIn returns the matcher of the blacklisted namespaces of the cluster target
+func NamespaceBlacklistIn(cluster string) (*NamespaceMatcher, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return live().namespaceBlacklistIn(cluster)
+}
+
+// NamespaceWhitelistIn returns the matcher of the whitelisted namespaces of the cluster target
+func NamespaceWhitelistIn(cluster string) (*NamespaceMatcher, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return live().namespaceWhitelistIn(cluster)
+}
+
+func DisabledVictimKinds() sets.String {
+	// Return as set for O(1) membership checks
+	return sets.NewString(read(settings.disabledVictimKinds)...)
+}
+
+func CustomResources() []CustomResource {
+	return read(settings.customResources)
+}
+
+func ChaosPoliciesEnabled() bool {
+	return read(settings.chaosPoliciesEnabled)
+}
+
+func ChaosExperimentsEnabled() bool {
+	return read(settings.chaosExperimentsEnabled)
+}
+
+func StatusAddress() string {
+	return read(settings.statusAddress)
 }
 
 func ClusterAPIServerHost() (string, bool) {
-	if viper.IsSet(param.ClusterAPIServerHost) {
-		return viper.GetString(param.ClusterAPIServerHost), true
+	mu.RLock()
+	defer mu.RUnlock()
+	if live().IsSet(param.ClusterAPIServerHost) {
+		return live().GetString(param.ClusterAPIServerHost), true
 	}
 	return "", false
 }
 
+func Kubeconfig() string {
+	return read(settings.kubeconfig)
+}
+
+func KubeContext() string {
+	return read(settings.kubeContext)
+}
+
+func ImpersonateUser() string {
+	return read(settings.impersonateUser)
+}
+
+func ImpersonateGroups() []string {
+	return read(settings.impersonateGroups)
+}
+
+func ClientQPS() float32 {
+	return read(settings.clientQPS)
+}
+
+func ClientBurst() int {
+	return read(settings.clientBurst)
+}
+
+func ClientUserAgent() string {
+	return read(settings.clientUserAgent)
+}
+
 func DebugEnabled() bool {
-	return viper.GetBool(param.DebugEnabled)
+	return read(settings.debugEnabled)
 }
 
 func DebugScheduleDelay() time.Duration {
-	delaySec := viper.GetInt(param.DebugScheduleDelay)
-	return time.Duration(delaySec) * time.Second
+	return read(settings.debugScheduleDelay)
 }
 
 func DebugForceShouldKill() bool {
-	return viper.GetBool(param.DebugForceShouldKill)
+	return read(settings.debugForceShouldKill)
 }
 
 func DebugScheduleImmediateKill() bool {
-	return viper.GetBool(param.DebugScheduleImmediateKill)
+	return read(settings.debugScheduleImmediateKill)
 }
 
 func NotificationsEnabled() bool {
-	return viper.GetBool(param.NotificationsEnabled)
+	return read(settings.notificationsEnabled)
 }
 
 func NotificationsProxy() string {
-	return viper.GetString(param.NotificationsProxy)
+	return read(settings.notificationsProxy)
 }
 
 func NotificationsReportSchedule() bool {
-	return viper.GetBool(param.NotificationsReportSchedule)
+	return read(settings.notificationsReportSchedule)
 }
 
 func NotificationsAttacks() Receiver {
-	var receiver Receiver
-	err := viper.UnmarshalKey(param.NotificationsAttacks, &receiver)
-	if err != nil {
-		glog.Errorf("Failed to parse notifications.attacks %v", err)
-	}
-	return receiver
+	return read(settings.notificationsAttacks)
 }
@@ -30,8 +30,16 @@ func (s *ConfigTestSuite) TestSetDefaults() {
 	s.Equal(10, viper.GetInt(param.StartHour))
 	s.Equal(16, viper.GetInt(param.EndHour))
 	s.Equal(int64(5), viper.GetInt64(param.GracePeriodSec))
+	s.Equal(0, viper.GetInt(param.CooldownHours))
+	s.Equal(600, viper.GetInt(param.RecoveryTimeoutSec))
+	s.Equal(PodPolicyReady, viper.GetString(param.PodSelectionPolicy))
+	s.True(viper.GetBool(param.ClusterWideDiscovery))
+	s.Equal(float32(5), ClientQPS())
+	s.Equal(10, ClientBurst())
+	s.Equal("kube-monkey", ClientUserAgent())
 	s.Equal([]string{metav1.NamespaceSystem}, viper.GetStringSlice(param.BlacklistedNamespaces))
 	s.Equal([]string{metav1.NamespaceAll}, viper.GetStringSlice(param.WhitelistedNamespaces))
+	s.Empty(viper.GetStringSlice(param.DisabledVictimKinds))
 	s.False(viper.GetBool(param.DebugEnabled))
 	s.Equal(viper.GetInt(param.DebugScheduleDelay), 30)
 	s.False(viper.GetBool(param.DebugForceShouldKill))
@@ -80,6 +88,22 @@ func (s *ConfigTestSuite) TestGracePeriodSeconds() {
 	s.Equal(&g, GracePeriodSeconds())
 }
 
+func (s *ConfigTestSuite) TestCooldown() {
+	s.Equal(time.Duration(0), Cooldown())
+	viper.Set(param.CooldownHours, 48)
+	s.Equal(48*time.Hour, Cooldown())
+}
+
+func (s *ConfigTestSuite) TestRecoveryTimeout() {
+	viper.Set(param.RecoveryTimeoutSec, 30)
+	s.Equal(30*time.Second, RecoveryTimeout())
+}
+
+func (s *ConfigTestSuite) TestPodSelectionPolicy() {
+	viper.Set(param.PodSelectionPolicy, PodPolicyNotTerminating)
+	s.Equal(PodPolicyNotTerminating, PodSelectionPolicy())
+}
+
 func (s *ConfigTestSuite) TestBlacklistedNamespacesEnv() {
 	blns := []string{"namespace3", "namespace4"}
 	envname := "KUBEMONKEY_BLACKLISTED_NAMESPACES"
@@ -116,12 +140,41 @@ func (s *ConfigTestSuite) TestBlacklistEnabled() {
 	s.True(BlacklistEnabled())
 	viper.Set(param.BlacklistedNamespaces, []string{metav1.NamespaceNone})
 	s.False(BlacklistEnabled())
+	viper.Set(param.BlacklistedNamespaceSelector, "tier=critical")
+	s.True(BlacklistEnabled())
 }
 
 func (s *ConfigTestSuite) TestWhitelistEnabled() {
 	s.False(WhitelistEnabled())
 	viper.Set(param.WhitelistedNamespaces, []string{metav1.NamespaceDefault})
 	s.True(WhitelistEnabled())
+	viper.Set(param.WhitelistedNamespaces, []string{metav1.NamespaceAll})
+	viper.Set(param.WhitelistedNamespaceSelector, "env=staging")
+	s.True(WhitelistEnabled())
+}
+
+func (s *ConfigTestSuite) TestDisabledVictimKinds() {
+	s.Empty(DisabledVictimKinds())
+	viper.Set(param.DisabledVictimKinds, []string{"daemonsets"})
+	s.True(DisabledVictimKinds().Has("daemonsets"))
+}
+
+func (s *ConfigTestSuite) TestCustomResources() {
+	s.Empty(CustomResources())
+
+	resources := []map[string]interface{}{
+		{"group": "example.com", "version": "v1", "resource": "widgets"},
+		{"group": "example.com", "version": "v1", "resource": "gadgets", "pod_selection": PodSelectionSelector, "selector_path": "spec.selector"},
+	}
+	viper.Set(param.CustomResources, resources)
+	actual := CustomResources()
+
+	s.Len(actual, 2)
+	s.Equal("widgets", actual[0].Resource)
+	s.Equal(PodSelectionIdentifier, actual[0].PodSelection)
+	s.Equal(PodSelectionSelector, actual[1].PodSelection)
+	s.Equal("spec.selector", actual[1].SelectorPath)
+	s.Equal("example.com/v1, Resource=gadgets", actual[1].GroupVersionResource().String())
 }
 
 func (s *ConfigTestSuite) TestClusterrAPIServerHost() {
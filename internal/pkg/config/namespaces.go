@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// NamespaceMatcher matches namespaces against a list of entries and
+// a label selector. Entries are matched as
+//   - a regular expression when enclosed in slashes, e.g. "/^team-[a-z]+$/"
+//   - a glob pattern when containing *, ? or [, e.g. "team-*"
+//   - a namespace name otherwise
+//
+// Empty entries, i.e. metav1.NamespaceAll, never match a namespace
+type NamespaceMatcher struct {
+	names    sets.String
+	globs    []string
+	regexps  []*regexp.Regexp
+	selector labels.Selector
+}
+
+// NewNamespaceMatcher parses the entries and the label selector
+// An empty selector matches no namespace
+func NewNamespaceMatcher(entries []string, selector string) (*NamespaceMatcher, error) {
+	m := &NamespaceMatcher{names: sets.NewString()}
+
+	for _, entry := range entries {
+		switch {
+		case entry == "":
+			continue
+		case len(entry) > 1 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/"):
+			re, err := regexp.Compile(entry[1 : len(entry)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespace regular expression %s: %v", entry, err)
+			}
+			m.regexps = append(m.regexps, re)
+		case strings.ContainsAny(entry, "*?["):
+			if _, err := path.Match(entry, ""); err != nil {
+				return nil, fmt.Errorf("invalid namespace pattern %s: %v", entry, err)
+			}
+			m.globs = append(m.globs, entry)
+		default:
+			m.names.Insert(entry)
+		}
+	}
+
+	if selector != "" {
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector %s: %v", selector, err)
+		}
+		m.selector = s
+	}
+
+	return m, nil
+}
+
+// Matches checks if the namespace with the labels matches an entry or the selector
+func (m *NamespaceMatcher) Matches(namespace string, namespaceLabels map[string]string) bool {
+	if m.names.Has(namespace) {
+		return true
+	}
+	for _, glob := range m.globs {
+		if matched, _ := path.Match(glob, namespace); matched {
+			return true
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(namespace) {
+			return true
+		}
+	}
+	return m.selector != nil && m.selector.Matches(labels.Set(namespaceLabels))
+}
+
+// Names returns the namespace names of the entries, and false if
+// namespaces must be listed to be matched, i.e. if the matcher has
+// patterns or a selector
+func (m *NamespaceMatcher) Names() ([]string, bool) {
+	if len(m.globs) > 0 || len(m.regexps) > 0 || m.selector != nil {
+		return nil, false
+	}
+	return m.names.List(), true
+}
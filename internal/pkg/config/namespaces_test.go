@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceMatcherMatches(t *testing.T) {
+	m, err := NewNamespaceMatcher([]string{"default", "team-*", "/^env-(dev|qa)$/", ""}, "env=staging")
+	assert.NoError(t, err)
+
+	assert.True(t, m.Matches("default", nil), "Expected name to match")
+	assert.True(t, m.Matches("team-a", nil), "Expected glob to match")
+	assert.True(t, m.Matches("env-qa", nil), "Expected regular expression to match")
+	assert.True(t, m.Matches("other", map[string]string{"env": "staging"}), "Expected selector to match")
+
+	assert.False(t, m.Matches("env-prod", nil))
+	assert.False(t, m.Matches("team", nil))
+	assert.False(t, m.Matches("other", map[string]string{"env": "prod"}))
+	assert.False(t, m.Matches("", nil), "Expected empty entries to match no namespace")
+}
+
+func TestNamespaceMatcherNames(t *testing.T) {
+	m, err := NewNamespaceMatcher([]string{"b", "a"}, "")
+	assert.NoError(t, err)
+	names, ok := m.Names()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	m, err = NewNamespaceMatcher([]string{"a", "team-*"}, "")
+	assert.NoError(t, err)
+	_, ok = m.Names()
+	assert.False(t, ok, "Expected patterns to require listing namespaces")
+
+	m, err = NewNamespaceMatcher([]string{"a"}, "env=staging")
+	assert.NoError(t, err)
+	_, ok = m.Names()
+	assert.False(t, ok, "Expected a selector to require listing namespaces")
+}
+
+func TestNewNamespaceMatcherInvalid(t *testing.T) {
+	_, err := NewNamespaceMatcher([]string{"/team-(/"}, "")
+	assert.Error(t, err)
+
+	_, err = NewNamespaceMatcher([]string{"team-["}, "")
+	assert.Error(t, err)
+
+	_, err = NewNamespaceMatcher(nil, "env in (")
+	assert.Error(t, err)
+}
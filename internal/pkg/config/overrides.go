@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// Serializes the changes of the config file and of the overrides
+	changeMu sync.Mutex
+
+	// The contents of the config file in effect and the values overriding
+	// it, from which the overridden config is built. Guarded by mu
+	file      []byte
+	overrides = map[string]interface{}{}
+
+	// The values of the command line flags, which take precedence over
+	// the config file and the overrides. Guarded by mu
+	flags = map[string]interface{}{}
+)
+
+// SetFlag sets the value of a command line flag, which takes precedence
+// over the config file and the overrides, and is kept when they change
+func SetFlag(key string, value interface{}) {
+	changeMu.Lock()
+	defer changeMu.Unlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	flags[key] = value
+	viper.Set(key, value)
+	if overridden != nil {
+		overridden.Set(key, value)
+	}
+}
+
+// SetOverrides replaces the values overriding the config file, e.g. the
+// values of ChaosPolicy resources, keyed by param. The config with the new
+// overrides is built and validated aside, and only replaces the live
+// config if it is valid
+func SetOverrides(values map[string]interface{}) error {
+	changeMu.Lock()
+	defer changeMu.Unlock()
+
+	candidate, err := WithOverrides(values)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	overrides = values
+	overridden = nil
+	if len(values) > 0 {
+		overridden = candidate
+	}
+	return nil
+}
+
+// Overrides returns the values overriding the config file, keyed by param
+func Overrides() map[string]interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	values := make(map[string]interface{}, len(overrides))
+	for key, value := range overrides {
+		values[key] = value
+	}
+	return values
+}
+
+// WithOverrides returns the config of the config file with the values
+// overriding it, built aside from the live config, or its ValidationErrors
+func WithOverrides(values map[string]interface{}) (*viper.Viper, error) {
+	mu.RLock()
+	data := file
+	mu.RUnlock()
+
+	candidate, err := build(data, values)
+	if err != nil {
+		return nil, err
+	}
+	if errs := (settings{candidate}).validate(); len(errs) > 0 {
+		return nil, errs
+	}
+	return candidate, nil
+}
+
+// WhitelistEnabledWith checks if namespaces are whitelisted in the config,
+// e.g. a config returned by WithOverrides
+func WhitelistEnabledWith(v *viper.Viper) bool {
+	return settings{v}.whitelistEnabledIn("")
+}
+
+// Builds the config of the defaults, the contents of the config file,
+// the values overriding them and the command line flags
+func build(data []byte, values map[string]interface{}) (*viper.Viper, error) {
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigType(configtype)
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		v.Set(key, value)
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for key, value := range flags {
+		v.Set(key, value)
+	}
+	return v, nil
+}
+
+// Loads the contents of the config file into the live config, keeping
+// the overrides in effect. The new config is built and validated aside,
+// and the live config is left untouched if it is unreadable or invalid
+func loadFile(data []byte) error {
+	changeMu.Lock()
+	defer changeMu.Unlock()
+
+	mu.RLock()
+	values := overrides
+	mu.RUnlock()
+
+	candidate, err := build(data, values)
+	if err != nil {
+		return err
+	}
+	if errs := (settings{candidate}).validate(); len(errs) > 0 {
+		return errs
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	file = data
+	overridden = nil
+	if len(values) > 0 {
+		overridden = candidate
+	}
+	return nil
+}
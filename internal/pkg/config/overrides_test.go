@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"kube-monkey/internal/pkg/config/param"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOverrides(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+	defer func() { _ = SetOverrides(map[string]interface{}{}) }()
+
+	assert.NoError(t, SetOverrides(map[string]interface{}{param.RunHour: 6, param.DryRun: false}))
+	assert.Equal(t, 6, RunHour())
+	assert.False(t, DryRun())
+
+	assert.NoError(t, SetOverrides(map[string]interface{}{param.RunHour: 7}))
+	assert.Equal(t, 7, RunHour())
+	assert.True(t, DryRun(), "Expected removed overrides to fall back to the default")
+	assert.Equal(t, map[string]interface{}{param.RunHour: 7}, Overrides())
+
+	err := SetOverrides(map[string]interface{}{param.RunHour: 24})
+	assert.EqualError(t, err, "RunHour: "+param.RunHour+" is outside valid range of [0,23]")
+	assert.Equal(t, 7, RunHour(), "Expected the previous overrides to be restored")
+	assert.Equal(t, map[string]interface{}{param.RunHour: 7}, Overrides())
+}
+
+func TestSetOverridesConcurrently(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+	defer func() { _ = SetOverrides(map[string]interface{}{}) }()
+
+	require.NoError(t, SetOverrides(map[string]interface{}{param.DryRun: false}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for hour := 0; hour < 100; hour++ {
+			_ = SetOverrides(map[string]interface{}{param.RunHour: hour % 8, param.DryRun: false})
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			require.False(t, DryRun(), "Expected the overrides to be swapped at once")
+		}
+	}
+}
+
+func TestSetFlagSurvivesOverridesAndReloads(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer func() {
+		flags = map[string]interface{}{}
+		_ = SetOverrides(map[string]interface{}{})
+		viper.Reset()
+		SetDefaults()
+	}()
+
+	SetFlag(param.KubeContext, "staging")
+	SetFlag(param.DryRun, true)
+	assert.Equal(t, "staging", KubeContext())
+
+	require.NoError(t, SetOverrides(map[string]interface{}{param.RunHour: 6, param.DryRun: false}))
+	assert.Equal(t, 6, RunHour())
+	assert.Equal(t, "staging", KubeContext(), "Expected the flag to survive the overrides")
+	assert.True(t, DryRun(), "Expected the flag to take precedence over the overrides")
+
+	require.NoError(t, loadFile([]byte("[kubernetes]\ncontext = \"production\"\n")))
+	assert.Equal(t, "staging", KubeContext(), "Expected the flag to survive a reload of the config file")
+
+	SetFlag(param.KubeContext, "ci")
+	assert.Equal(t, "ci", KubeContext(), "Expected a flag set with overrides in effect to apply at once")
+}
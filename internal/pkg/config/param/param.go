@@ -44,23 +44,119 @@ const (
 	// Default: 5
 	GracePeriodSec = "kubemonkey.graceperiod_sec"
 
+	// CooldownHours specifies the minimum number of hours
+	// that must pass after a victim was last attacked before
+	// it can be scheduled for termination again
+	// Victims can override it with the kube-monkey/cooldown-hours label
+	// Use 0 to disable the cooldown
+	// Type: int
+	// Default: 0
+	CooldownHours = "kubemonkey.cooldown_hours"
+
 	// WhitelistedNamespaces specifies a list of
 	// namespaces where terminations are valid
+	// Entries are namespace names, glob patterns such as "team-*",
+	// or regular expressions enclosed in slashes such as "/^team-[a-z]+$/"
 	// Default is defined by metav1.NamespaceDefault
 	// To allow all namespaces use [""]
 	// Type: list
 	// Default: [ "default" ]
 	WhitelistedNamespaces = "kubemonkey.whitelisted_namespaces"
 
+	// WhitelistedNamespaceSelector specifies a label selector
+	// of namespaces where terminations are valid, e.g. "env=staging"
+	// Namespaces matching either the selector or an entry
+	// of WhitelistedNamespaces are whitelisted
+	// Type: string
+	// Default: ""
+	WhitelistedNamespaceSelector = "kubemonkey.whitelisted_namespace_selector"
+
 	// BlacklistedNamespaces specifies a list of namespaces
 	// for which terminations should never
 	// be carried out.
+	// Entries are namespace names, glob patterns or regular
+	// expressions, as in WhitelistedNamespaces
 	// Default is defined by metav1.NamespaceSystem
 	// To block no namespaces use [""]
 	// Type: list
 	// Default: [ "kube-system" ]
 	BlacklistedNamespaces = "kubemonkey.blacklisted_namespaces"
 
+	// BlacklistedNamespaceSelector specifies a label selector
+	// of namespaces for which terminations should never be
+	// carried out, e.g. "tier=critical"
+	// Type: string
+	// Default: ""
+	BlacklistedNamespaceSelector = "kubemonkey.blacklisted_namespace_selector"
+
+	// RecoveryTimeoutSec specifies the amount of time in
+	// seconds kube-monkey waits for a victim that can verify its
+	// recovery, such as a Job, to recover after an attack
+	// Type: int
+	// Default: 600
+	RecoveryTimeoutSec = "kubemonkey.recovery_timeout_sec"
+
+	// PodSelectionPolicy specifies which pods of a victim are
+	// counted and selected for termination:
+	//   "running": pods in the Running phase
+	//   "not-terminating": running pods that are not terminating already
+	//   "ready": running pods that are not terminating and are Ready
+	// Type: string
+	// Default: "ready"
+	PodSelectionPolicy = "kubemonkey.pod_selection_policy"
+
+	// ClusterWideDiscovery specifies if the victims of the
+	// whitelisted namespaces are discovered with a single
+	// cluster-wide List per kind, filtered locally, instead
+	// of one List per kind and namespace. Disable it when
+	// kube-monkey is only allowed to list in some namespaces
+	// Type: bool
+	// Default: true
+	ClusterWideDiscovery = "kubemonkey.cluster_wide_discovery"
+
+	// DisabledVictimKinds specifies a list of victim kinds
+	// that are never scheduled for termination, e.g. "daemonsets"
+	// Built-in kinds are "deployments", "statefulsets", "daemonsets",
+	// "jobs", "cronjobs", "replicasets", "pods",
+	// "clusters.postgresql.cnpg.io" and "rollouts.argoproj.io".
+	// Custom resources are
+	// named <resource>.<group>
+	// Type: list
+	// Default: []
+	DisabledVictimKinds = "kubemonkey.disabled_victim_kinds"
+
+	// CustomResources specifies a list of custom resource kinds
+	// that can be enrolled as victims, in addition to the built-in kinds
+	// Each entry has the group, version and resource of the kind,
+	// and how to find the pods of a resource with pod_selection:
+	//   "identifier": pods labelled with the kube-monkey/identifier label
+	//   "selector": pods matching the label selector found in the
+	//               resource at selector_path, e.g. "spec.selector"
+	//   "owner": pods with an owner reference to the resource
+	// Type: list of config.CustomResource
+	// Default: []
+	CustomResources = "kubemonkey.custom_resources"
+
+	// ChaosPoliciesEnabled enables the ChaosPolicy and ChaosTarget
+	// custom resources of the kubemonkey.io group, which are watched
+	// and merged into the config read from the config file
+	// Type: bool
+	// Default: false
+	ChaosPoliciesEnabled = "kubemonkey.chaos_policies_enabled"
+
+	// StatusAddress is the address of the HTTP endpoint serving the
+	// status of kube-monkey at /status, e.g. ":8080"
+	// Type: string
+	// Default: No default. If not specified, the endpoint is disabled
+	StatusAddress = "kubemonkey.status_address"
+
+	// ChaosExperimentsEnabled enables the ChaosExperiment custom
+	// resource of the kubemonkey.io group, which runs an attack of
+	// a workload on demand and reports its result in its status
+	// Type: bool
+	// Default: false
+	ChaosExperimentsEnabled = "kubemonkey.chaos_experiments_enabled"
+
 	// ClusterAPIServerHost specifies the host URL for Kubernetes
 	// cluster APIServer. Use this config if the apiserver IP
 	// address provided by in-cluster config
@@ -71,6 +167,68 @@ const (
 	// by in-cluster config is used
 	ClusterAPIServerHost = "kubernetes.host"
 
+	// Kubeconfig specifies the path of the kubeconfig file used
+	// to reach the apiserver, e.g. to run kube-monkey out of
+	// cluster. When neither Kubeconfig nor KubeContext is set,
+	// the in-cluster config is used if available, and otherwise
+	// the files of the KUBECONFIG environment variable or ~/.kube/config
+	// Type: string
+	// Default: ""
+	Kubeconfig = "kubernetes.kubeconfig"
+
+	// KubeContext specifies the context of the kubeconfig file
+	// Type: string
+	// Default: "", i.e. the current context of the kubeconfig file
+	KubeContext = "kubernetes.context"
+
+	// ImpersonateUser specifies the user kube-monkey impersonates
+	// when querying the apiserver
+	// Type: string
+	// Default: "", i.e. no impersonation
+	ImpersonateUser = "kubernetes.impersonate_user"
+
+	// ImpersonateGroups specifies the groups kube-monkey impersonates
+	// when querying the apiserver
+	// Type: list
+	// Default: []
+	ImpersonateGroups = "kubernetes.impersonate_groups"
+
+	// Clusters specifies the clusters targeted by one kube-monkey
+	// instance. Each cluster is reached through its kubeconfig
+	// file, e.g. mounted from a secret, and context, and can
+	// override the whitelisted and blacklisted namespaces:
+	//   [[kubernetes.clusters]]
+	//   name = "staging"
+	//   kubeconfig = "/etc/kube-monkey/clusters/staging"
+	//   context = "staging"
+	//   whitelisted_namespaces = ["team-*"]
+	//   blacklisted_namespaces = ["kube-system"]
+	// The name of the cluster tags its schedule, results and
+	// notifications in place of KUBE_MONKEY_ID.
+	// When empty, only the cluster configured by Kubeconfig
+	// and KubeContext, or the in-cluster config, is targeted
+	// Type: list of config.ClusterTarget
+	// Default: []
+	Clusters = "kubernetes.clusters"
+
+	// ClientQPS specifies the maximum queries per second
+	// sent by kube-monkey to the apiserver
+	// Type: float
+	// Default: 5
+	ClientQPS = "kubernetes.qps"
+
+	// ClientBurst specifies the maximum burst of queries
+	// sent by kube-monkey to the apiserver above ClientQPS
+	// Type: int
+	// Default: 10
+	ClientBurst = "kubernetes.burst"
+
+	// ClientUserAgent specifies the user agent of the
+	// queries sent by kube-monkey to the apiserver
+	// Type: string
+	// Default: "kube-monkey"
+	ClientUserAgent = "kubernetes.user_agent"
+
 	// DebugEnabled enables debug mode
 	// Type: bool
 	// Default: false
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// ReloadStatus is the status of the config file, which is reloaded
+// whenever it changes
+type ReloadStatus struct {
+	// Generation is incremented for every accepted config file
+	Generation int64     `json:"generation"`
+	LoadedAt   time.Time `json:"loadedAt"`
+
+	// Valid is false if the last change of the config file was rejected,
+	// in which case the previous config file remains in effect
+	Valid      bool       `json:"valid"`
+	Error      string     `json:"error,omitempty"`
+	RejectedAt *time.Time `json:"rejectedAt,omitempty"`
+}
+
+var (
+	reloadMu     sync.Mutex
+	reloadStatus ReloadStatus
+	onReject     []func(error)
+)
+
+// Status returns the status of the config file
+func Status() ReloadStatus {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	return reloadStatus
+}
+
+// OnReject registers a handler called with the validation error
+// whenever a change of the config file is rejected
+func OnReject(handler func(error)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	onReject = append(onReject, handler)
+}
+
+// Reloads the config file, and keeps the previous config
+// if it is unreadable or invalid
+func reloadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = loadFile(data)
+	}
+	if err != nil {
+		rejectConfig(err)
+		return err
+	}
+
+	acceptConfig()
+	return nil
+}
+
+// Records that a new config file is in effect
+func acceptConfig() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	reloadStatus.Generation++
+	reloadStatus.LoadedAt = time.Now()
+	reloadStatus.Valid = true
+	reloadStatus.Error = ""
+}
+
+// Records that a change of the config file was rejected
+// and notifies the handlers
+func rejectConfig(err error) {
+	reloadMu.Lock()
+	now := time.Now()
+	reloadStatus.Valid = false
+	reloadStatus.Error = err.Error()
+	reloadStatus.RejectedAt = &now
+	handlers := append([]func(error){}, onReject...)
+	reloadMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(err)
+	}
+}
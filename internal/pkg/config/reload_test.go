@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kube-monkey/internal/pkg/config/param"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadFile(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	viper.SetConfigType(configtype)
+	defer viper.Reset()
+
+	handlers := onReject
+	defer func() { onReject = handlers }()
+	var rejected []error
+	OnReject(func(err error) { rejected = append(rejected, err) })
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	write := func(contents string) {
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	}
+
+	write("[kubemonkey]\nrun_hour = 6\n")
+	require.NoError(t, reloadFile(path))
+	generation := Status().Generation
+	assert.Equal(t, 6, RunHour())
+	assert.True(t, Status().Valid)
+
+	write("[kubemonkey]\nrun_hour = 24\n")
+	err := reloadFile(path)
+	assert.EqualError(t, err, "RunHour: "+param.RunHour+" is outside valid range of [0,23]")
+	assert.Equal(t, 6, RunHour(), "Expected the previous config to be kept")
+	assert.False(t, Status().Valid)
+	assert.Equal(t, err.Error(), Status().Error)
+	assert.NotNil(t, Status().RejectedAt)
+	assert.Equal(t, generation, Status().Generation)
+	assert.Equal(t, []error{err}, rejected)
+
+	write("[kubemonkey\nrun_hour = 7\n")
+	assert.Error(t, reloadFile(path))
+	assert.Equal(t, 6, RunHour(), "Expected the previous config to be kept")
+	assert.Len(t, rejected, 2)
+
+	write("[kubemonkey]\nrun_hour = 7\n")
+	require.NoError(t, reloadFile(path))
+	assert.Equal(t, 7, RunHour())
+	assert.True(t, Status().Valid)
+	assert.Empty(t, Status().Error)
+	assert.Equal(t, generation+1, Status().Generation)
+}
+
+func TestReloadFileInvalidNeverLive(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	viper.SetConfigType(configtype)
+	defer func() {
+		viper.Reset()
+		SetDefaults()
+	}()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[kubemonkey]\nrun_hour = 6\n"), 0600))
+	require.NoError(t, reloadFile(path))
+	require.NoError(t, os.WriteFile(path, []byte("[kubemonkey]\nrun_hour = 24\n"), 0600))
+
+	done := make(chan struct{})
+	seen := make(chan int, 1)
+	go func() {
+		defer close(seen)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if hour := RunHour(); hour != 6 {
+				seen <- hour
+				return
+			}
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		assert.Error(t, reloadFile(path))
+	}
+	close(done)
+
+	for hour := range seen {
+		assert.Failf(t, "invalid config in effect", "Expected the invalid run hour to never be read, got %d", hour)
+	}
+	assert.Equal(t, 6, viper.GetInt(param.RunHour), "Expected the invalid config file to never be loaded")
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/viper"
+
+	"kube-monkey/internal/pkg/config/param"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var (
+	// Guards the live config, as viper is not safe for concurrent use.
+	// The getters read it under the read lock, and reloads and overrides
+	// swap it under the write lock once the new config is validated
+	mu sync.RWMutex
+
+	// The config file with the overrides of SetOverrides, read by the
+	// getters instead of the global viper while there are overrides
+	overridden *viper.Viper
+)
+
+// settings reads the params of a config, e.g. a config being
+// validated before it replaces the live config
+type settings struct {
+	*viper.Viper
+}
+
+// Returns the live config. The caller holds mu
+func live() settings {
+	if overridden != nil {
+		return settings{overridden}
+	}
+	return settings{viper.GetViper()}
+}
+
+// Reads the live config under the read lock
+func read[T any](get func(settings) T) T {
+	mu.RLock()
+	defer mu.RUnlock()
+	return get(live())
+}
+
+func (s settings) dryRun() bool {
+	return s.GetBool(param.DryRun)
+}
+
+func (s settings) timezone() *time.Location {
+	tz := s.GetString(param.Timezone)
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		glog.Fatal(err.Error())
+	}
+	return location
+}
+
+func (s settings) runHour() int {
+	return s.GetInt(param.RunHour)
+}
+
+func (s settings) startHour() int {
+	return s.GetInt(param.StartHour)
+}
+
+func (s settings) endHour() int {
+	return s.GetInt(param.EndHour)
+}
+
+func (s settings) gracePeriodSeconds() *int64 {
+	gpInt64 := s.GetInt64(param.GracePeriodSec)
+	return &gpInt64
+}
+
+func (s settings) cooldown() time.Duration {
+	hours := s.GetInt(param.CooldownHours)
+	return time.Duration(hours) * time.Hour
+}
+
+func (s settings) recoveryTimeout() time.Duration {
+	timeoutSec := s.GetInt(param.RecoveryTimeoutSec)
+	return time.Duration(timeoutSec) * time.Second
+}
+
+func (s settings) podSelectionPolicy() string {
+	return s.GetString(param.PodSelectionPolicy)
+}
+
+func (s settings) clusterWideDiscovery() bool {
+	return s.GetBool(param.ClusterWideDiscovery)
+}
+
+func (s settings) blacklistedNamespaceSelector() string {
+	return s.GetString(param.BlacklistedNamespaceSelector)
+}
+
+func (s settings) whitelistedNamespaceSelector() string {
+	return s.GetString(param.WhitelistedNamespaceSelector)
+}
+
+func (s settings) blacklistEnabledIn(cluster string) bool {
+	return !sets.NewString(s.blacklistedNamespacesIn(cluster)...).Equal(sets.NewString(metav1.NamespaceNone)) || s.blacklistedNamespaceSelector() != ""
+}
+
+func (s settings) whitelistEnabledIn(cluster string) bool {
+	return !sets.NewString(s.whitelistedNamespacesIn(cluster)...).Equal(sets.NewString(metav1.NamespaceAll)) || s.whitelistedNamespaceSelector() != ""
+}
+
+func (s settings) namespaceBlacklistIn(cluster string) (*NamespaceMatcher, error) {
+	return NewNamespaceMatcher(s.blacklistedNamespacesIn(cluster), s.blacklistedNamespaceSelector())
+}
+
+func (s settings) namespaceWhitelistIn(cluster string) (*NamespaceMatcher, error) {
+	return NewNamespaceMatcher(s.whitelistedNamespacesIn(cluster), s.whitelistedNamespaceSelector())
+}
+
+func (s settings) disabledVictimKinds() []string {
+	return s.GetStringSlice(param.DisabledVictimKinds)
+}
+
+func (s settings) customResources() []CustomResource {
+	var resources []CustomResource
+	err := s.UnmarshalKey(param.CustomResources, &resources)
+	if err != nil {
+		glog.Errorf("Failed to parse %s %v", param.CustomResources, err)
+	}
+
+	for i := range resources {
+		if resources[i].PodSelection == "" {
+			resources[i].PodSelection = PodSelectionIdentifier
+		}
+	}
+	return resources
+}
+
+func (s settings) chaosPoliciesEnabled() bool {
+	return s.GetBool(param.ChaosPoliciesEnabled)
+}
+
+func (s settings) chaosExperimentsEnabled() bool {
+	return s.GetBool(param.ChaosExperimentsEnabled)
+}
+
+func (s settings) statusAddress() string {
+	return s.GetString(param.StatusAddress)
+}
+
+func (s settings) kubeconfig() string {
+	return s.GetString(param.Kubeconfig)
+}
+
+func (s settings) kubeContext() string {
+	return s.GetString(param.KubeContext)
+}
+
+func (s settings) impersonateUser() string {
+	return s.GetString(param.ImpersonateUser)
+}
+
+func (s settings) impersonateGroups() []string {
+	return s.GetStringSlice(param.ImpersonateGroups)
+}
+
+func (s settings) clientQPS() float32 {
+	return float32(s.GetFloat64(param.ClientQPS))
+}
+
+func (s settings) clientBurst() int {
+	return s.GetInt(param.ClientBurst)
+}
+
+func (s settings) clientUserAgent() string {
+	return s.GetString(param.ClientUserAgent)
+}
+
+func (s settings) debugEnabled() bool {
+	return s.GetBool(param.DebugEnabled)
+}
+
+func (s settings) debugScheduleDelay() time.Duration {
+	delaySec := s.GetInt(param.DebugScheduleDelay)
+	return time.Duration(delaySec) * time.Second
+}
+
+func (s settings) debugForceShouldKill() bool {
+	return s.GetBool(param.DebugForceShouldKill)
+}
+
+func (s settings) debugScheduleImmediateKill() bool {
+	return s.GetBool(param.DebugScheduleImmediateKill)
+}
+
+func (s settings) notificationsEnabled() bool {
+	return s.GetBool(param.NotificationsEnabled)
+}
+
+func (s settings) notificationsProxy() string {
+	return s.GetString(param.NotificationsProxy)
+}
+
+func (s settings) notificationsReportSchedule() bool {
+	return s.GetBool(param.NotificationsReportSchedule)
+}
+
+func (s settings) notificationsAttacks() Receiver {
+	var receiver Receiver
+	err := s.UnmarshalKey(param.NotificationsAttacks, &receiver)
+	if err != nil {
+		glog.Errorf("Failed to parse notifications.attacks %v", err)
+	}
+	return receiver
+}
@@ -2,58 +2,272 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
+	"time"
 
 	"kube-monkey/internal/pkg/config/param"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ValidationError is an invalid value of the config key Key
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// ValidationErrors are all the invalid values of the config
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Placeholders of the notification messages, kept in sync with
+// those replaced by package notifications
+var messagePlaceholders = sets.NewString("name", "kind", "namespace", "timestamp", "time", "date", "error", "outcome", "recovery", "kubemonkeyid")
+
+var (
+	placeholderRegex           = regexp.MustCompile(`\{\$([^}]*)\}`)
+	misspelledPlaceholderRegex = regexp.MustCompile(`\$\{(\w+)\}`)
 )
 
+// ValidateConfigs returns the ValidationErrors of the config, if any
 func ValidateConfigs() error {
+	if errs := Validate(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate checks the whole config and returns all its invalid values
+func Validate() ValidationErrors {
+	return read(settings.validate)
+}
+
+// Checks the config and returns all its invalid values
+func (s settings) validate() ValidationErrors {
+	var errs ValidationErrors
+	invalid := func(key string, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
 	// RunHour should be [0, 23]
-	runHour := RunHour()
-	if !IsValidHour(runHour) {
-		return fmt.Errorf("RunHour: %s is outside valid range of [0,23]", param.RunHour)
+	runHour := s.runHour()
+	validRunHour := IsValidHour(runHour)
+	if !validRunHour {
+		invalid(param.RunHour, "RunHour: %s is outside valid range of [0,23]", param.RunHour)
 	}
 
 	// StartHour should be [0, 23]
-	startHour := StartHour()
-	if !IsValidHour(startHour) {
-		return fmt.Errorf("StartHour: %s is outside valid range of [0,23]", param.StartHour)
+	startHour := s.startHour()
+	validStartHour := IsValidHour(startHour)
+	if !validStartHour {
+		invalid(param.StartHour, "StartHour: %s is outside valid range of [0,23]", param.StartHour)
 	}
 
 	// EndHour should be [0, 23]
-	endHour := EndHour()
-	if !IsValidHour(endHour) {
-		return fmt.Errorf("EndHour: %s is outside valid range of [0,23]", param.EndHour)
+	endHour := s.endHour()
+	validEndHour := IsValidHour(endHour)
+	if !validEndHour {
+		invalid(param.EndHour, "EndHour: %s is outside valid range of [0,23]", param.EndHour)
 	}
 
 	// StartHour should be < EndHour
-	if !(startHour < endHour) {
-		return fmt.Errorf("StartHour: %s must be less than %s", param.StartHour, param.EndHour)
+	if validStartHour && validEndHour && !(startHour < endHour) {
+		invalid(param.StartHour, "StartHour: %s must be less than %s", param.StartHour, param.EndHour)
 	}
 
 	// RunHour should be < StartHour
-	if !(runHour < startHour) {
-		return fmt.Errorf("RunHour: %s should be less than %s", param.RunHour, param.StartHour)
+	if validRunHour && validStartHour && !(runHour < startHour) {
+		invalid(param.RunHour, "RunHour: %s should be less than %s", param.RunHour, param.StartHour)
+	}
+
+	// Timezone should be a known tzdata zone
+	if timezone := s.GetString(param.Timezone); timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			invalid(param.Timezone, "Timezone: %s has unknown time zone %s", param.Timezone, timezone)
+		}
+	}
+
+	// Durations should not be negative
+	if *s.gracePeriodSeconds() < 0 {
+		invalid(param.GracePeriodSec, "GracePeriodSec: %s must not be negative", param.GracePeriodSec)
+	}
+	if s.cooldown() < 0 {
+		invalid(param.CooldownHours, "CooldownHours: %s must not be negative", param.CooldownHours)
+	}
+
+	// Namespace patterns and selectors should be valid, and
+	// namespaces should not be both whitelisted and blacklisted
+	whitelist, err := s.namespaceWhitelistIn("")
+	if err != nil {
+		invalid(param.WhitelistedNamespaces, "WhitelistedNamespaces: %v", err)
+	}
+	blacklist, err := s.namespaceBlacklistIn("")
+	if err != nil {
+		invalid(param.BlacklistedNamespaces, "BlacklistedNamespaces: %v", err)
+	}
+	if whitelist != nil && blacklist != nil {
+		if overlap := whitelist.names.Intersection(blacklist.names); overlap.Len() > 0 {
+			invalid(param.WhitelistedNamespaces, "WhitelistedNamespaces: %s are also blacklisted in %s", strings.Join(overlap.List(), ", "), param.BlacklistedNamespaces)
+		}
+	}
+
+	// PodSelectionPolicy should be a known policy
+	switch s.podSelectionPolicy() {
+	case PodPolicyRunning, PodPolicyNotTerminating, PodPolicyReady:
+	default:
+		invalid(param.PodSelectionPolicy, "PodSelectionPolicy: %s must be one of %s, %s or %s", param.PodSelectionPolicy, PodPolicyRunning, PodPolicyNotTerminating, PodPolicyReady)
+	}
+
+	// Custom resources should be fully specified
+	for _, resource := range s.customResources() {
+		if err := validateCustomResource(resource); err != nil {
+			invalid(param.CustomResources, "%v", err)
+		}
+	}
+
+	// Impersonated groups require an impersonated user
+	if len(s.impersonateGroups()) > 0 && s.impersonateUser() == "" {
+		invalid(param.ImpersonateGroups, "ImpersonateGroups: %s requires %s", param.ImpersonateGroups, param.ImpersonateUser)
+	}
+
+	// Cluster targets should be uniquely named with valid namespaces,
+	// which should not be both whitelisted and blacklisted
+	for _, problem := range clusterErrors(s.clusters(), s.GetStringSlice(param.WhitelistedNamespaces), s.GetStringSlice(param.BlacklistedNamespaces)) {
+		invalid(param.Clusters, "Clusters: %s", problem)
+	}
+
+	// Client rate limits should be positive
+	if s.clientQPS() <= 0 || s.clientBurst() <= 0 {
+		invalid(param.ClientQPS, "ClientQPS: %s and %s must be positive", param.ClientQPS, param.ClientBurst)
 	}
 
-	notificationsReceiver := NotificationsAttacks()
+	notificationsReceiver := s.notificationsAttacks()
+
+	// Notification endpoint should be an HTTP URL
+	if notificationsReceiver.Endpoint != "" {
+		if !isValidEndpoint(notificationsReceiver.Endpoint) {
+			invalid(param.NotificationsAttacks, "NotificationsAttacks: endpoint %s of %s must be an http or https URL", notificationsReceiver.Endpoint, param.NotificationsAttacks)
+		}
+	} else if s.notificationsEnabled() {
+		invalid(param.NotificationsAttacks, "NotificationsAttacks: %s requires an endpoint when %s is true", param.NotificationsAttacks, param.NotificationsEnabled)
+	}
 
 	// Notification headers should be in a valid format
 	for _, header := range notificationsReceiver.Headers {
 		if !isValidHeader(header) {
-			return fmt.Errorf("Header: %s is not in valid format", header)
+			invalid(param.NotificationsAttacks, "Header: %s is not in valid format", header)
 		}
 	}
 
-	return nil
+	// Notification message should only use known placeholders
+	for _, message := range messageErrors(notificationsReceiver.Message) {
+		invalid(param.NotificationsAttacks, "NotificationsAttacks: message %s", message)
+	}
+
+	return errs
 }
 
 func IsValidHour(hour int) bool {
 	return hour >= 0 && hour < 24
 }
 
+func validateCustomResource(resource CustomResource) error {
+	if resource.Version == "" || resource.Resource == "" {
+		return fmt.Errorf("CustomResources: %s entries require a version and a resource", param.CustomResources)
+	}
+
+	switch resource.PodSelection {
+	case PodSelectionIdentifier, PodSelectionOwner:
+	case PodSelectionSelector:
+		if resource.SelectorPath == "" {
+			return fmt.Errorf("CustomResources: %s requires a selector_path for pod_selection %s", resource.GroupVersionResource(), PodSelectionSelector)
+		}
+	default:
+		return fmt.Errorf("CustomResources: %s has invalid pod_selection %s", resource.GroupVersionResource(), resource.PodSelection)
+	}
+
+	return nil
+}
+
+// Returns the problems of the cluster targets. The namespaces of a cluster
+// default to the global whitelisted and blacklisted namespaces
+func clusterErrors(clusters []ClusterTarget, whitelisted, blacklisted []string) (problems []string) {
+	names := map[string]bool{}
+	for _, cluster := range clusters {
+		if cluster.Name == "" {
+			problems = append(problems, fmt.Sprintf("%s entries require a name", param.Clusters))
+		} else if names[cluster.Name] {
+			problems = append(problems, fmt.Sprintf("cluster %s is defined more than once", cluster.Name))
+		}
+		names[cluster.Name] = true
+
+		// The namespaces a cluster does not set are the global ones,
+		// which are checked on their own
+		if cluster.WhitelistedNamespaces == nil && cluster.BlacklistedNamespaces == nil {
+			continue
+		}
+
+		whitelist, whitelistErr := NewNamespaceMatcher(namespacesOr(cluster.WhitelistedNamespaces, whitelisted), "")
+		if whitelistErr != nil && cluster.WhitelistedNamespaces != nil {
+			problems = append(problems, fmt.Sprintf("whitelisted namespaces of cluster %s: %v", cluster.Name, whitelistErr))
+		}
+		blacklist, blacklistErr := NewNamespaceMatcher(namespacesOr(cluster.BlacklistedNamespaces, blacklisted), "")
+		if blacklistErr != nil && cluster.BlacklistedNamespaces != nil {
+			problems = append(problems, fmt.Sprintf("blacklisted namespaces of cluster %s: %v", cluster.Name, blacklistErr))
+		}
+
+		if whitelistErr == nil && blacklistErr == nil {
+			if overlap := whitelist.names.Intersection(blacklist.names); overlap.Len() > 0 {
+				problems = append(problems, fmt.Sprintf("whitelisted namespaces of cluster %s: %s are also blacklisted", cluster.Name, strings.Join(overlap.List(), ", ")))
+			}
+		}
+	}
+	return problems
+}
+
+// Returns the namespaces set for a cluster, or the global ones if unset
+func namespacesOr(namespaces, global []string) []string {
+	if namespaces == nil {
+		return global
+	}
+	return namespaces
+}
+
 func isValidHeader(header string) bool {
 	re := regexp.MustCompile("^(.+:.+)$")
 
 	return re.MatchString(header)
 }
+
+func isValidEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Returns the problems of the placeholders of a notification message
+func messageErrors(message string) (problems []string) {
+	for _, match := range placeholderRegex.FindAllStringSubmatch(message, -1) {
+		if !messagePlaceholders.Has(match[1]) {
+			problems = append(problems, fmt.Sprintf("has unknown placeholder %s", match[0]))
+		}
+	}
+	for _, match := range misspelledPlaceholderRegex.FindAllStringSubmatch(message, -1) {
+		if messagePlaceholders.Has(match[1]) {
+			problems = append(problems, fmt.Sprintf("has placeholder %s, which should be written {$%s}", match[0], match[1]))
+		}
+	}
+	return problems
+}
@@ -23,11 +23,11 @@ func TestValidateConfigs(t *testing.T) {
 	viper.Set(param.StartHour, 23)
 
 	viper.Set(param.EndHour, 24)
-	assert.EqualError(t, ValidateConfigs(), "EndHour: "+param.EndHour+" is outside valid range of [0,23]")
+	assert.EqualError(t, ValidateConfigs(), "EndHour: "+param.EndHour+" is outside valid range of [0,23]; RunHour: "+param.RunHour+" should be less than "+param.StartHour)
 	viper.Set(param.EndHour, 23)
 
 	viper.Set(param.StartHour, 23)
-	assert.EqualError(t, ValidateConfigs(), "StartHour: "+param.StartHour+" must be less than "+param.EndHour)
+	assert.EqualError(t, ValidateConfigs(), "StartHour: "+param.StartHour+" must be less than "+param.EndHour+"; RunHour: "+param.RunHour+" should be less than "+param.StartHour)
 	viper.Set(param.StartHour, 22)
 
 	viper.Set(param.RunHour, 23)
@@ -35,6 +35,131 @@ func TestValidateConfigs(t *testing.T) {
 
 }
 
+func TestValidateAggregatesErrors(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+
+	viper.Set(param.RunHour, 24)
+	viper.Set(param.Timezone, "Mars/Olympus_Mons")
+	viper.Set(param.GracePeriodSec, -1)
+	viper.Set(param.WhitelistedNamespaces, []string{"app", "kube-system"})
+	viper.Set(param.NotificationsEnabled, true)
+	viper.Set(param.NotificationsAttacks, map[string]interface{}{
+		"endpoint": "receiver:8080",
+		"message":  "Attacked {$name} of ${kubemonkeyid} in {$cluster}",
+	})
+
+	errs := Validate()
+	var keys, messages []string
+	for _, err := range errs {
+		keys = append(keys, err.Key)
+		messages = append(messages, err.Message)
+	}
+
+	assert.Equal(t, []string{
+		param.RunHour,
+		param.Timezone,
+		param.GracePeriodSec,
+		param.WhitelistedNamespaces,
+		param.NotificationsAttacks,
+		param.NotificationsAttacks,
+		param.NotificationsAttacks,
+	}, keys)
+	assert.Equal(t, []string{
+		"RunHour: " + param.RunHour + " is outside valid range of [0,23]",
+		"Timezone: " + param.Timezone + " has unknown time zone Mars/Olympus_Mons",
+		"GracePeriodSec: " + param.GracePeriodSec + " must not be negative",
+		"WhitelistedNamespaces: kube-system are also blacklisted in " + param.BlacklistedNamespaces,
+		"NotificationsAttacks: endpoint receiver:8080 of " + param.NotificationsAttacks + " must be an http or https URL",
+		"NotificationsAttacks: message has unknown placeholder {$cluster}",
+		"NotificationsAttacks: message has placeholder ${kubemonkeyid}, which should be written {$kubemonkeyid}",
+	}, messages)
+	assert.EqualError(t, ValidateConfigs(), errs.Error())
+}
+
+func TestValidateNotificationsEndpoint(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+
+	viper.Set(param.NotificationsEnabled, true)
+	assert.EqualError(t, ValidateConfigs(), "NotificationsAttacks: "+param.NotificationsAttacks+" requires an endpoint when "+param.NotificationsEnabled+" is true")
+
+	viper.Set(param.NotificationsAttacks, map[string]interface{}{"endpoint": "https://hooks.example.com/kube-monkey", "message": "{$kind} {$name}: {$outcome}"})
+	assert.Nil(t, ValidateConfigs())
+}
+
+func TestValidatePodSelectionPolicy(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+
+	for _, policy := range []string{PodPolicyRunning, PodPolicyNotTerminating, PodPolicyReady} {
+		viper.Set(param.PodSelectionPolicy, policy)
+		assert.Nil(t, ValidateConfigs())
+	}
+
+	viper.Set(param.PodSelectionPolicy, "healthy")
+	assert.EqualError(t, ValidateConfigs(), "PodSelectionPolicy: "+param.PodSelectionPolicy+" must be one of running, not-terminating or ready")
+}
+
+func TestValidateNamespaceMatchers(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+
+	viper.Set(param.WhitelistedNamespaces, []string{"team-*", "/^env-.+$/"})
+	viper.Set(param.BlacklistedNamespaceSelector, "tier=critical")
+	assert.Nil(t, ValidateConfigs())
+
+	viper.Set(param.WhitelistedNamespaces, []string{"/env-(/"})
+	assert.ErrorContains(t, ValidateConfigs(), "WhitelistedNamespaces: invalid namespace regular expression /env-(/")
+	viper.Set(param.WhitelistedNamespaces, []string{""})
+
+	viper.Set(param.BlacklistedNamespaceSelector, "tier in (")
+	assert.ErrorContains(t, ValidateConfigs(), "BlacklistedNamespaces: invalid namespace selector tier in (")
+}
+
+func TestValidateClientRateLimits(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+
+	viper.Set(param.ClientQPS, 0)
+	assert.EqualError(t, ValidateConfigs(), "ClientQPS: "+param.ClientQPS+" and "+param.ClientBurst+" must be positive")
+	viper.Set(param.ClientQPS, 20)
+
+	viper.Set(param.ClientBurst, -1)
+	assert.EqualError(t, ValidateConfigs(), "ClientQPS: "+param.ClientQPS+" and "+param.ClientBurst+" must be positive")
+}
+
+func TestValidateImpersonation(t *testing.T) {
+	viper.Reset()
+	SetDefaults()
+	defer viper.Reset()
+
+	viper.Set(param.ImpersonateGroups, []string{"chaos-engineers"})
+	assert.EqualError(t, ValidateConfigs(), "ImpersonateGroups: "+param.ImpersonateGroups+" requires "+param.ImpersonateUser)
+
+	viper.Set(param.ImpersonateUser, "chaos")
+	assert.Nil(t, ValidateConfigs())
+}
+
+func TestValidateCustomResource(t *testing.T) {
+	resource := CustomResource{Group: "example.com", Version: "v1", Resource: "widgets", PodSelection: PodSelectionIdentifier}
+	assert.Nil(t, validateCustomResource(resource))
+
+	resource.PodSelection = PodSelectionSelector
+	assert.EqualError(t, validateCustomResource(resource), "CustomResources: example.com/v1, Resource=widgets requires a selector_path for pod_selection selector")
+
+	resource.PodSelection = "unknown"
+	assert.EqualError(t, validateCustomResource(resource), "CustomResources: example.com/v1, Resource=widgets has invalid pod_selection unknown")
+
+	resource.Version = ""
+	assert.EqualError(t, validateCustomResource(resource), "CustomResources: "+param.CustomResources+" entries require a version and a resource")
+}
+
 func TestIsValidHour(t *testing.T) {
 	for i := 0; i <= 23; i++ {
 		assert.True(t, IsValidHour(i))
@@ -0,0 +1,304 @@
+/*
+Package experiments runs the ChaosExperiment custom resources
+
+A ChaosExperiment attacks an enrolled workload in its namespace once, at
+its start time, with the kill mode of the experiment. The attack goes
+through the same checks as the scheduled ones, e.g. the namespace
+blacklist, and its progress and result are reported in the status of
+the experiment.
+*/
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/chaos"
+	"kube-monkey/internal/pkg/kubernetes"
+	"kube-monkey/internal/pkg/victims/factory"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+)
+
+// ResyncPeriod is the period at which the experiments are checked again
+const ResyncPeriod = 10 * time.Minute
+
+// IsAvailable checks if the ChaosExperiment custom resource is served by the apiserver
+func IsAvailable(client dynamic.Interface) bool {
+	_, err := client.Resource(ChaosExperimentGVR).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	return err == nil
+}
+
+// Controller watches the ChaosExperiment resources and runs them
+type Controller struct {
+	clients kubernetes.ClientProvider
+	client  dynamic.Interface
+
+	// The experiments waiting for their start time or running
+	mu        sync.Mutex
+	scheduled map[types.UID]*time.Timer
+}
+
+// NewController creates a controller of the experiments of the cluster
+// of the clients, which are also used to attack the targets
+func NewController(clients kubernetes.ClientProvider) (*Controller, error) {
+	_, client, err := clients.Clients()
+	if err != nil {
+		return nil, err
+	}
+	return &Controller{
+		clients:   clients,
+		client:    client,
+		scheduled: map[types.UID]*time.Timer{},
+	}, nil
+}
+
+// Start watches the experiments and runs them at their start time
+// until stopCh is closed. It returns once the experiments are listed
+func (c *Controller) Start(stopCh <-chan struct{}) error {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(c.client, ResyncPeriod)
+	informer := factory.ForResource(ChaosExperimentGVR).Informer()
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onChange,
+		UpdateFunc: func(_, obj interface{}) { c.onChange(obj) },
+		DeleteFunc: c.onDelete,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch chaos experiments: %v", err)
+	}
+
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return fmt.Errorf("failed to sync %s", ChaosExperimentGVR.GroupResource())
+	}
+	glog.V(1).Infof("Watching %s", ChaosExperimentGVR.GroupResource())
+	return nil
+}
+
+func (c *Controller) onChange(obj interface{}) {
+	if experiment, ok := obj.(*unstructured.Unstructured); ok {
+		c.Handle(experiment)
+	}
+}
+
+// Cancels the experiment if it did not start yet
+func (c *Controller) onDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	experiment, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if timer, ok := c.scheduled[experiment.GetUID()]; ok {
+		timer.Stop()
+		delete(c.scheduled, experiment.GetUID())
+	}
+}
+
+// Handle schedules the experiment at its start time, unless it is
+// scheduled or completed already
+func (c *Controller) Handle(experiment *unstructured.Unstructured) {
+	status := statusOf(experiment)
+	if status.Completed() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.scheduled[experiment.GetUID()]; ok {
+		return
+	}
+
+	namespace, name := experiment.GetNamespace(), experiment.GetName()
+
+	// Experiments are not resumed after a restart, as their target may
+	// have been attacked already
+	if status.Phase == PhaseRunning {
+		c.setStatus(namespace, name, func(status *ChaosExperimentStatus) bool {
+			if status.Phase != PhaseRunning {
+				return false
+			}
+			*status = failed(*status, fmt.Errorf("interrupted by a restart of kube-monkey"))
+			return true
+		})
+		return
+	}
+
+	spec, err := specOf(experiment)
+	if err != nil {
+		c.setStatus(namespace, name, func(status *ChaosExperimentStatus) bool {
+			*status = failed(*status, err)
+			return true
+		})
+		return
+	}
+
+	startAt := experiment.GetCreationTimestamp().Time
+	if spec.StartTime != nil {
+		startAt = spec.StartTime.Time
+	}
+
+	c.setStatus(namespace, name, func(status *ChaosExperimentStatus) bool {
+		if status.Phase != "" {
+			return false
+		}
+		status.Phase = PhasePending
+		status.Message = fmt.Sprintf("Scheduled at %s", startAt.Format(time.RFC3339))
+		return true
+	})
+
+	uid := experiment.GetUID()
+	c.scheduled[uid] = time.AfterFunc(time.Until(startAt), func() {
+		c.Run(namespace, name, spec, startAt)
+
+		c.mu.Lock()
+		delete(c.scheduled, uid)
+		c.mu.Unlock()
+	})
+}
+
+// Run attacks the target of the experiment and reports the result
+// in its status
+func (c *Controller) Run(namespace, name string, spec ChaosExperimentSpec, startAt time.Time) {
+	glog.V(2).Infof("Running ChaosExperiment %s/%s against %s %s", namespace, name, spec.Target.Kind, spec.Target.Name)
+	c.setStatus(namespace, name, func(status *ChaosExperimentStatus) bool {
+		now := metav1.Now()
+		status.Phase = PhaseRunning
+		status.Message = fmt.Sprintf("Attacking %s %s", spec.Target.Kind, spec.Target.Name)
+		status.StartedAt = &now
+		return true
+	})
+
+	result := c.attack(namespace, spec, startAt)
+	c.setStatus(namespace, name, func(status *ChaosExperimentStatus) bool {
+		*status = completed(*status, result)
+		return true
+	})
+}
+
+// Attacks the target through the chaos of the experiment
+func (c *Controller) attack(namespace string, spec ChaosExperimentSpec, startAt time.Time) *chaos.Result {
+	victim, err := factory.FindVictim("", c.clients, spec.Target.Kind, namespace, spec.Target.Name)
+	if err != nil {
+		return chaos.NewResult(nil, err)
+	}
+
+	resultchan := make(chan *chaos.Result, 1)
+	chaos.NewWithKillMode(startAt, victim, spec.Fault, spec.KillValue).Execute(c.clients, resultchan)
+	return <-resultchan
+}
+
+// Returns the status of an experiment completed with the result
+func completed(status ChaosExperimentStatus, result *chaos.Result) ChaosExperimentStatus {
+	switch {
+	case result.Skipped():
+		now := metav1.Now()
+		status.Phase = PhaseSkipped
+		status.Message = result.Error().Error()
+		status.CompletedAt = &now
+		return status
+	case result.Error() != nil:
+		status.PodsKilled = result.PodsKilled()
+		return failed(status, result.Error())
+	}
+
+	now := metav1.Now()
+	status.Phase = PhaseSucceeded
+	status.Message = fmt.Sprintf("Killed %d pods", result.PodsKilled())
+	status.CompletedAt = &now
+	status.PodsKilled = result.PodsKilled()
+	if recovery := result.Recovery(); recovery != nil {
+		status.Recovered = &recovery.Recovered
+		status.RecoveryTime = recovery.Duration.Round(time.Second).String()
+		if recovery.Err != nil {
+			status.Error = recovery.Err.Error()
+		}
+	}
+	return status
+}
+
+// Returns the status of an experiment that failed with err
+func failed(status ChaosExperimentStatus, err error) ChaosExperimentStatus {
+	now := metav1.Now()
+	status.Phase = PhaseFailed
+	status.Message = "Experiment failed"
+	status.Error = err.Error()
+	status.CompletedAt = &now
+	return status
+}
+
+// Updates the status of the latest version of the experiment with
+// update, which returns false if the status must not change
+func (c *Controller) setStatus(namespace, name string, update func(*ChaosExperimentStatus) bool) {
+	resources := c.client.Resource(ChaosExperimentGVR).Namespace(namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		experiment, err := resources.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		status := statusOf(experiment)
+		if !update(&status) {
+			return nil
+		}
+
+		fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedMap(experiment.Object, fields, "status"); err != nil {
+			return err
+		}
+
+		_, err = resources.UpdateStatus(context.TODO(), experiment, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		glog.Errorf("Failed to update status of ChaosExperiment %s/%s. Error: %v", namespace, name, err)
+	}
+}
+
+// Parses and validates the spec of the experiment
+func specOf(experiment *unstructured.Unstructured) (ChaosExperimentSpec, error) {
+	var spec ChaosExperimentSpec
+	fields, _, err := unstructured.NestedMap(experiment.Object, "spec")
+	if err == nil {
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(fields, &spec)
+	}
+	if err != nil {
+		return spec, fmt.Errorf("invalid spec: %v", err)
+	}
+
+	switch {
+	case spec.Target.Kind == "" || spec.Target.Name == "":
+		return spec, fmt.Errorf("invalid spec: target.kind and target.name are required")
+	case spec.Fault == "":
+		return spec, fmt.Errorf("invalid spec: fault is required")
+	}
+	return spec, nil
+}
+
+// Returns the status of the experiment
+func statusOf(experiment *unstructured.Unstructured) ChaosExperimentStatus {
+	var status ChaosExperimentStatus
+	if fields, ok, _ := unstructured.NestedMap(experiment.Object, "status"); ok {
+		_ = runtime.DefaultUnstructuredConverter.FromUnstructured(fields, &status)
+	}
+	return status
+}
@@ -0,0 +1,141 @@
+package experiments
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/kubernetes"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newExperiment(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetAPIVersion(ChaosExperimentGVR.GroupVersion().String())
+	obj.SetKind("ChaosExperiment")
+	obj.SetNamespace("app")
+	obj.SetName(name)
+	obj.SetUID(types.UID("uid-" + name))
+	return obj
+}
+
+func newRunningPod(name string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", Labels: labels},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func newController(t *testing.T, experiments []runtime.Object, objects ...runtime.Object) *Controller {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		ChaosExperimentGVR: "ChaosExperimentList",
+	}, experiments...)
+	controller, err := NewController(kubernetes.NewStaticClientProvider(fake.NewSimpleClientset(objects...), dynamicClient))
+	require.NoError(t, err)
+	return controller
+}
+
+func experimentStatus(t *testing.T, controller *Controller, name string) ChaosExperimentStatus {
+	experiment, err := controller.client.Resource(ChaosExperimentGVR).Namespace("app").Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return statusOf(experiment)
+}
+
+func TestRun(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	enrolled := map[string]string{config.EnabledLabelKey: config.EnabledLabelValue, config.MtbfLabelKey: "1"}
+	experiment := newExperiment("kill-web", map[string]interface{}{
+		"target": map[string]interface{}{"kind": "pods", "name": "web"},
+		"fault":  config.KillAllLabelValue,
+	})
+	controller := newController(t, []runtime.Object{experiment}, newRunningPod("web", enrolled))
+
+	spec, err := specOf(experiment)
+	require.NoError(t, err)
+	controller.Run("app", "kill-web", spec, time.Now())
+
+	status := experimentStatus(t, controller, "kill-web")
+	assert.Equal(t, PhaseSucceeded, status.Phase)
+	assert.Equal(t, 1, status.PodsKilled)
+	assert.NotNil(t, status.StartedAt)
+	assert.NotNil(t, status.CompletedAt)
+	assert.Empty(t, status.Error)
+}
+
+func TestRunNotEnrolled(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	experiment := newExperiment("kill-web", map[string]interface{}{
+		"target": map[string]interface{}{"kind": "pods", "name": "web"},
+		"fault":  config.KillFixedLabelValue,
+	})
+	controller := newController(t, []runtime.Object{experiment}, newRunningPod("web", nil))
+
+	spec, err := specOf(experiment)
+	require.NoError(t, err)
+	controller.Run("app", "kill-web", spec, time.Now())
+
+	status := experimentStatus(t, controller, "kill-web")
+	assert.Equal(t, PhaseFailed, status.Phase)
+	assert.Equal(t, "pods app/web is not enrolled in kube-monkey", status.Error)
+}
+
+func TestHandle(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	later := newExperiment("later", map[string]interface{}{
+		"target":    map[string]interface{}{"kind": "pods", "name": "web"},
+		"fault":     config.KillFixedLabelValue,
+		"killValue": int64(1),
+		"startTime": time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	})
+	invalid := newExperiment("invalid", map[string]interface{}{
+		"target": map[string]interface{}{"kind": "pods"},
+	})
+	interrupted := newExperiment("interrupted", map[string]interface{}{
+		"target": map[string]interface{}{"kind": "pods", "name": "web"},
+		"fault":  config.KillFixedLabelValue,
+	})
+	interrupted.Object["status"] = map[string]interface{}{"phase": PhaseRunning}
+
+	controller := newController(t, []runtime.Object{later, invalid, interrupted})
+	for _, experiment := range []*unstructured.Unstructured{later, invalid, interrupted} {
+		controller.Handle(experiment)
+	}
+
+	assert.Equal(t, PhasePending, experimentStatus(t, controller, "later").Phase)
+	assert.Contains(t, controller.scheduled, later.GetUID())
+
+	status := experimentStatus(t, controller, "invalid")
+	assert.Equal(t, PhaseFailed, status.Phase)
+	assert.Equal(t, "invalid spec: target.kind and target.name are required", status.Error)
+
+	status = experimentStatus(t, controller, "interrupted")
+	assert.Equal(t, PhaseFailed, status.Phase)
+	assert.Equal(t, "interrupted by a restart of kube-monkey", status.Error)
+
+	controller.onDelete(later)
+	assert.NotContains(t, controller.scheduled, later.GetUID(), "Expected the deleted experiment to be cancelled")
+}
@@ -0,0 +1,60 @@
+package experiments
+
+import (
+	"kube-monkey/internal/pkg/policies"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Phases of a ChaosExperiment
+const (
+	PhasePending   = "Pending"
+	PhaseRunning   = "Running"
+	PhaseSucceeded = "Succeeded"
+	PhaseFailed    = "Failed"
+	PhaseSkipped   = "Skipped"
+)
+
+// ChaosExperimentGVR identifies the namespaced ChaosExperiment resources
+var ChaosExperimentGVR = schema.GroupVersionResource{Group: policies.Group, Version: policies.Version, Resource: "chaosexperiments"}
+
+// ChaosExperimentSpec is the spec of a ChaosExperiment, which attacks
+// a workload in the namespace of the experiment once
+type ChaosExperimentSpec struct {
+	Target TargetSpec `json:"target"`
+
+	// Fault is the kill mode of the attack, e.g. "fixed" or "kill-all",
+	// overriding the kill mode labels of the target
+	Fault     string `json:"fault"`
+	KillValue int    `json:"killValue,omitempty"`
+
+	// StartTime is the time of the attack, defaulting to its creation
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
+
+// TargetSpec names the workload attacked by an experiment
+type TargetSpec struct {
+	// Kind is the victim kind, as listed in config.DisabledVictimKinds,
+	// e.g. "deployments" or "rollouts.argoproj.io"
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// ChaosExperimentStatus is the progress and result of a ChaosExperiment
+type ChaosExperimentStatus struct {
+	Phase       string       `json:"phase,omitempty"`
+	Message     string       `json:"message,omitempty"`
+	StartedAt   *metav1.Time `json:"startedAt,omitempty"`
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	PodsKilled   int    `json:"podsKilled,omitempty"`
+	Recovered    *bool  `json:"recovered,omitempty"`
+	RecoveryTime string `json:"recoveryTime,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Completed checks if the experiment ran, successfully or not
+func (s ChaosExperimentStatus) Completed() bool {
+	return s.Phase == PhaseSucceeded || s.Phase == PhaseFailed || s.Phase == PhaseSkipped
+}
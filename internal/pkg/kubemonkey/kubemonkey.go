@@ -2,6 +2,7 @@ package kubemonkey
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/golang/glog"
@@ -9,9 +10,12 @@ import (
 	"kube-monkey/internal/pkg/calendar"
 	"kube-monkey/internal/pkg/chaos"
 	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/experiments"
 	"kube-monkey/internal/pkg/kubernetes"
 	"kube-monkey/internal/pkg/notifications"
+	"kube-monkey/internal/pkg/policies"
 	"kube-monkey/internal/pkg/schedule"
+	"kube-monkey/internal/pkg/status"
 )
 
 func durationToNextRun(runhour int, loc *time.Location) time.Duration {
@@ -28,19 +32,52 @@ func durationToNextRun(runhour int, loc *time.Location) time.Duration {
 
 func Run() error {
 	// Verify kubernetes client can be created and works before
-	// we enter execution loop
-	if _, _, err := kubernetes.CreateClient(); err != nil {
-		return err
+	// we enter execution loop. The clients are shared by all runs
+	clients := kubernetes.NewClusterClientProviders()
+	for _, cluster := range clusterNames(clients) {
+		if err := clients[cluster].Healthy(); err != nil {
+			if cluster == "" {
+				return err
+			}
+			// Other clusters remain targeted if one is unreachable
+			glog.Errorf("Failed to verify client of cluster %s. Error: %v", cluster, err)
+		}
+	}
+
+	if config.ChaosPoliciesEnabled() {
+		if err := startPolicies(clients); err != nil {
+			return err
+		}
+	}
+
+	if config.ChaosExperimentsEnabled() {
+		if err := startExperiments(clients); err != nil {
+			return err
+		}
 	}
 
-	var notificationsClient notifications.Client
+	// Changes of the config or policies may enable the notifications later on
+	proxy := config.NotificationsProxy()
 	if config.NotificationsEnabled() {
 		glog.V(1).Infof("Notifications enabled!")
-		proxy := config.NotificationsProxy()
 		if proxy != "" {
 			glog.V(1).Infof("Notifications proxy set: %s!", proxy)
 		}
-		notificationsClient = notifications.CreateClient(&proxy)
+	}
+	notificationsClient := notifications.CreateClient(&proxy)
+
+	config.OnReject(func(err error) {
+		if config.NotificationsEnabled() {
+			notifications.ReportConfigRejected(notificationsClient, err)
+		}
+	})
+
+	if address := config.StatusAddress(); address != "" {
+		go func() {
+			if err := status.Serve(address); err != nil {
+				glog.Errorf("Failed to serve status on %s. Error: %v", address, err)
+			}
+		}()
 	}
 
 	for {
@@ -48,26 +85,88 @@ func Run() error {
 		sleepDuration := durationToNextRun(config.RunHour(), config.Timezone())
 		time.Sleep(sleepDuration)
 
-		schedule, err := schedule.New()
-		if err != nil {
-			glog.Fatal(err.Error())
-		}
-		schedule.Print()
-		if config.NotificationsEnabled() && config.NotificationsReportSchedule() {
-			notifications.ReportSchedule(notificationsClient, schedule)
+		var entries []*chaos.Chaos
+		for _, cluster := range clusterNames(clients) {
+			schedule, err := schedule.New(cluster, clients[cluster])
+			if err != nil {
+				if cluster == "" {
+					glog.Fatal(err.Error())
+				}
+				glog.Errorf("Failed to generate schedule of cluster %s. Error: %v", cluster, err)
+				continue
+			}
+			schedule.Print()
+			if config.NotificationsEnabled() && config.NotificationsReportSchedule() {
+				notifications.ReportSchedule(notificationsClient, schedule)
+			}
+			fmt.Println(schedule)
+			entries = append(entries, schedule.Entries()...)
 		}
-		fmt.Println(schedule)
-		ScheduleTerminations(schedule.Entries(), notificationsClient)
+		ScheduleTerminations(clients, entries, notificationsClient)
+	}
+}
+
+// Merges the ChaosPolicy and ChaosTarget resources of the cluster
+// kube-monkey runs in into the config for the lifetime of the process
+func startPolicies(clients map[string]kubernetes.ClientProvider) error {
+	_, dynamicClient, err := ownClients(clients).Clients()
+	if err != nil {
+		return err
+	}
+	if !policies.IsAvailable(dynamicClient) {
+		glog.Warningf("Chaos policies enabled but %s is not served by the apiserver", policies.ChaosPolicyGVR.GroupResource())
+		return nil
+	}
+	return policies.NewController(dynamicClient).Start(make(chan struct{}))
+}
+
+// Runs the ChaosExperiment resources of the cluster kube-monkey runs in
+// for the lifetime of the process
+func startExperiments(clients map[string]kubernetes.ClientProvider) error {
+	provider := ownClients(clients)
+	_, dynamicClient, err := provider.Clients()
+	if err != nil {
+		return err
+	}
+	if !experiments.IsAvailable(dynamicClient) {
+		glog.Warningf("Chaos experiments enabled but %s is not served by the apiserver", experiments.ChaosExperimentGVR.GroupResource())
+		return nil
 	}
+	controller, err := experiments.NewController(provider)
+	if err != nil {
+		return err
+	}
+	return controller.Start(make(chan struct{}))
+}
+
+// Returns the clients of the cluster kube-monkey runs in, which is the
+// unnamed cluster unless config.Clusters is set
+func ownClients(clients map[string]kubernetes.ClientProvider) kubernetes.ClientProvider {
+	if provider, ok := clients[""]; ok {
+		return provider
+	}
+	return kubernetes.NewClientProvider()
+}
+
+// Returns the names of the clusters in a stable order
+func clusterNames(clients map[string]kubernetes.ClientProvider) []string {
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
-func ScheduleTerminations(entries []*chaos.Chaos, notificationsClient notifications.Client) {
+// ScheduleTerminations executes the entries against the clients of the
+// cluster of their victim, and reports the results
+func ScheduleTerminations(clients map[string]kubernetes.ClientProvider, entries []*chaos.Chaos, notificationsClient notifications.Client) {
 	resultchan := make(chan *chaos.Result)
 	defer close(resultchan)
 
 	// Spin off all terminations
 	for _, chaos := range entries {
-		go chaos.Schedule(resultchan)
+		go chaos.Schedule(clients[chaos.Victim().Cluster()], resultchan)
 	}
 
 	completedCount := 0
@@ -78,18 +177,47 @@ func ScheduleTerminations(entries []*chaos.Chaos, notificationsClient notificati
 	// Gather results
 	for completedCount < len(entries) {
 		result = <-resultchan
-		if result.Error() != nil {
-			glog.Errorf("Failed to execute termination for %s %s. Error: %v", result.Victim().Kind(), result.Victim().Name(), result.Error().Error())
-		} else {
-			glog.V(2).Infof("Termination successfully executed for %s %s\n", result.Victim().Kind(), result.Victim().Name())
-		}
-		if config.NotificationsEnabled() {
-			currentTime := time.Now()
-			notifications.ReportAttack(notificationsClient, result, currentTime)
-		}
+		ReportResult(result, notificationsClient)
 		completedCount++
 		glog.V(4).Info("Status Update: ", len(entries)-completedCount, " scheduled terminations left.")
 	}
 
 	glog.V(3).Info("Status Update: All terminations done.")
 }
+
+// ReportResult logs the result of a termination, and reports it to the
+// notifications endpoint if notifications are enabled
+func ReportResult(result *chaos.Result, notificationsClient notifications.Client) {
+	if result.Skipped() {
+		glog.V(2).Infof("Skipped termination for %s: %v", victimName(result), result.Error())
+	} else if result.Error() != nil {
+		glog.Errorf("Failed to execute termination for %s. Error: %v", victimName(result), result.Error().Error())
+	} else {
+		glog.V(2).Infof("Termination successfully executed for %s\n", victimName(result))
+	}
+	if recovery := result.Recovery(); recovery != nil {
+		if recovery.Recovered {
+			glog.V(2).Infof("%s recovered %s after termination", victimName(result), recovery.Duration)
+		} else {
+			glog.Errorf("%s did not recover after termination. Error: %v", victimName(result), recovery.Err)
+		}
+	}
+	// The result is reported once the recovery is verified, at the time of the attack
+	if config.NotificationsEnabled() {
+		attackTime := result.AttackedAt()
+		if attackTime.IsZero() {
+			attackTime = time.Now()
+		}
+		notifications.ReportAttack(notificationsClient, result, attackTime)
+	}
+}
+
+// Returns the kind and name of the victim of the result, tagged with
+// its cluster if set
+func victimName(result *chaos.Result) string {
+	victim := result.Victim()
+	if victim.Cluster() != "" {
+		return fmt.Sprintf("%s %s in cluster %s", victim.Kind(), victim.Name(), victim.Cluster())
+	}
+	return fmt.Sprintf("%s %s", victim.Kind(), victim.Name())
+}
@@ -1,62 +1,139 @@
 /*
 Package kubernetes is the km k8 package that sets up the configured k8 clientset used to communicate with the apiserver
 
-Use CreateClient to create and verify connectivity.
-It's recommended to create a new clientset after a period of inactivity
+Use NewClientProvider to create the clients once and share them between
+the schedule and the executions of chaos.
 */
 package kubernetes
 
 import (
+	"context"
 	"fmt"
-	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/golang/glog"
 
 	cfg "kube-monkey/internal/pkg/config"
 
-	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	kube "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
 )
 
-// CreateClient creates, verifies and returns an instance of k8 clientset
-func CreateClient() (*kube.Clientset, dynamic.Interface, error) {
-	client, dynamicClient, err := NewClusterClient()
-	if err != nil {
-		return nil, nil, fmt.Errorf("Failed to generate NewInClusterClient: %v", err)
+// HealthCheckTimeout is the timeout of ClientProvider.Healthy
+const HealthCheckTimeout = 5 * time.Second
+
+// ClientProvider provides the clients used to communicate with the apiserver
+type ClientProvider interface {
+	// Clients returns the typed and dynamic clients
+	Clients() (kube.Interface, dynamic.Interface, error)
+	// Healthy checks the connectivity to the apiserver
+	Healthy() error
+}
+
+// clientProvider creates the clients on first use and reuses them afterwards
+type clientProvider struct {
+	restConfig func() (*rest.Config, error)
+
+	mu            sync.Mutex
+	clientset     kube.Interface
+	dynamicClient dynamic.Interface
+}
+
+// NewClientProvider creates a provider of long-lived clients built from
+// the config returned by RestConfig
+// The clients read their service account token from its file, and
+// kubeconfig credential plugins are called again when their credentials
+// expire, so the clients keep working when the credentials are rotated
+func NewClientProvider() ClientProvider {
+	kubeconfig, kubeContext := cfg.Kubeconfig(), cfg.KubeContext()
+	return &clientProvider{restConfig: func() (*rest.Config, error) {
+		return defaultRestConfig(kubeconfig, kubeContext)
+	}}
+}
+
+// NewClusterClientProvider creates a provider of long-lived clients of
+// the cluster target, built from its kubeconfig file and context
+func NewClusterClientProvider(cluster cfg.ClusterTarget) ClientProvider {
+	return &clientProvider{restConfig: func() (*rest.Config, error) {
+		return RestConfigFor(cluster.Kubeconfig, cluster.Context)
+	}}
+}
+
+// NewClusterClientProviders creates the providers of the clients of
+// config.Clusters by cluster name. Without cluster targets, the clients
+// of the unnamed cluster "" are created with NewClientProvider
+func NewClusterClientProviders() map[string]ClientProvider {
+	clusters := cfg.Clusters()
+	if len(clusters) == 0 {
+		return map[string]ClientProvider{"": NewClientProvider()}
 	}
 
-	if VerifyClient(client) {
-		return client, dynamicClient, nil
+	providers := make(map[string]ClientProvider, len(clusters))
+	for _, cluster := range clusters {
+		providers[cluster.Name] = NewClusterClientProvider(cluster)
 	}
-	return nil, nil, fmt.Errorf("Unable to verify client connectivity to Kubernetes apiserver")
+	return providers
 }
 
-// NewClusterClient only creates an initialized instance of k8 clientset
-func NewClusterClient() (*kube.Clientset, dynamic.Interface, error) {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		if err == rest.ErrNotInCluster {
-			// Attempt to use out of cluster config
-			config, err = clientcmd.BuildConfigFromFlags("", filepath.Join(homedir.HomeDir(), ".kube", "config"))
-			if err != nil {
-				glog.Errorf("failed to obtain config from kubeconfig file: %v", err)
-				return nil, nil, err
-			}
-		} else {
-			glog.Errorf("failed to obtain config from InClusterConfig: %v", err)
-			return nil, nil, err
+func (p *clientProvider) Clients() (kube.Interface, dynamic.Interface, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.clientset == nil {
+		config, err := p.restConfig()
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to generate NewInClusterClient: %v", err)
 		}
+		clientset, dynamicClient, err := newClients(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to generate NewInClusterClient: %v", err)
+		}
+		p.clientset, p.dynamicClient = clientset, dynamicClient
 	}
+	return p.clientset, p.dynamicClient, nil
+}
 
-	if apiserverHost, override := cfg.ClusterAPIServerHost(); override {
-		glog.V(5).Infof("API server host overridden to: %s\n", apiserverHost)
-		config.Host = apiserverHost
+func (p *clientProvider) Healthy() error {
+	clientset, _, err := p.Clients()
+	if err != nil {
+		return err
 	}
+	return CheckHealth(clientset)
+}
 
+// staticClientProvider provides the clients it was created with
+type staticClientProvider struct {
+	clientset     kube.Interface
+	dynamicClient dynamic.Interface
+}
+
+// NewStaticClientProvider creates a provider of existing clients
+func NewStaticClientProvider(clientset kube.Interface, dynamicClient dynamic.Interface) ClientProvider {
+	return &staticClientProvider{clientset: clientset, dynamicClient: dynamicClient}
+}
+
+func (p *staticClientProvider) Clients() (kube.Interface, dynamic.Interface, error) {
+	return p.clientset, p.dynamicClient, nil
+}
+
+func (p *staticClientProvider) Healthy() error {
+	return CheckHealth(p.clientset)
+}
+
+// NewClusterClient only creates an initialized instance of k8 clientset
+func NewClusterClient() (*kube.Clientset, dynamic.Interface, error) {
+	config, err := RestConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	return newClients(config)
+}
+
+// Creates the clients of the config
+func newClients(config *rest.Config) (*kube.Clientset, dynamic.Interface, error) {
 	clientset, err := kube.NewForConfig(config)
 	if err != nil {
 		glog.Errorf("failed to create clientset in NewForConfig: %v", err)
@@ -70,7 +147,100 @@ func NewClusterClient() (*kube.Clientset, dynamic.Interface, error) {
 	return clientset, dynamicClient, nil
 }
 
-func VerifyClient(client discovery.DiscoveryInterface) bool {
-	_, err := client.ServerVersion()
-	return err == nil
+// RestConfig creates the configured config of the clients of the
+// default, unnamed cluster
+func RestConfig() (*rest.Config, error) {
+	return defaultRestConfig(cfg.Kubeconfig(), cfg.KubeContext())
+}
+
+// Creates the config of the clients of the default cluster, which is the
+// only one the overridden apiserver host and the impersonation apply to.
+// The cluster targets of config.Clusters are reached through their own
+// kubeconfig files and contexts
+func defaultRestConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	config, err := RestConfigFor(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiserverHost, override := cfg.ClusterAPIServerHost(); override {
+		glog.V(5).Infof("API server host overridden to: %s\n", apiserverHost)
+		config.Host = apiserverHost
+	}
+
+	if user := cfg.ImpersonateUser(); user != "" {
+		glog.V(5).Infof("Impersonating user %s with groups %v", user, cfg.ImpersonateGroups())
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: user,
+			Groups:   cfg.ImpersonateGroups(),
+		}
+	}
+
+	return config, nil
+}
+
+// RestConfigFor creates the config of the clients of the kubeconfig
+// file and context. An explicit kubeconfig file or context takes
+// precedence over the in-cluster config, which takes precedence over
+// the kubeconfig files of the KUBECONFIG environment variable or ~/.kube/config.
+// The client rate limits and user agent apply to every cluster
+func RestConfigFor(kubeconfig, kubeContext string) (*rest.Config, error) {
+	var config *rest.Config
+	var err error
+	if kubeconfig != "" || kubeContext != "" {
+		config, err = kubeconfigConfig(kubeconfig, kubeContext)
+	} else {
+		config, err = rest.InClusterConfig()
+		if err == rest.ErrNotInCluster {
+			// Attempt to use out of cluster config
+			config, err = kubeconfigConfig(kubeconfig, kubeContext)
+		} else if err != nil {
+			glog.Errorf("failed to obtain config from InClusterConfig: %v", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config.QPS = cfg.ClientQPS()
+	config.Burst = cfg.ClientBurst()
+	config.UserAgent = cfg.ClientUserAgent()
+
+	return config, nil
+}
+
+// Loads the config from the kubeconfig file and context, defaulting
+// to the files of the KUBECONFIG environment variable or ~/.kube/config
+// and to the current context
+func kubeconfigConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		glog.Errorf("failed to obtain config from kubeconfig file: %v", err)
+		return nil, err
+	}
+	return config, nil
+}
+
+// CheckHealth queries the /livez endpoint of the apiserver, falling
+// back to its version for clients without a REST client, e.g. fakes
+func CheckHealth(clientset kube.Interface) error {
+	ctx, cancel := context.WithTimeout(context.Background(), HealthCheckTimeout)
+	defer cancel()
+
+	if restClient := clientset.Discovery().RESTClient(); restClient != nil {
+		if _, err := restClient.Get().AbsPath("/livez").DoRaw(ctx); err != nil {
+			return fmt.Errorf("Unable to verify client connectivity to Kubernetes apiserver: %v", err)
+		}
+		return nil
+	}
+
+	if _, err := clientset.Discovery().ServerVersion(); err != nil {
+		return fmt.Errorf("Unable to verify client connectivity to Kubernetes apiserver: %v", err)
+	}
+	return nil
 }
@@ -0,0 +1,164 @@
+package kubernetes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+)
+
+func TestStaticClientProvider(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clients := NewStaticClientProvider(clientset, nil)
+
+	kubeClient, dynamicClient, err := clients.Clients()
+	assert.NoError(t, err)
+	assert.Same(t, clientset, kubeClient)
+	assert.Nil(t, dynamicClient)
+
+	assert.NoError(t, clients.Healthy())
+}
+
+func TestCheckHealthUnreachable(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("get", "version", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	assert.EqualError(t, CheckHealth(clientset), "Unable to verify client connectivity to Kubernetes apiserver: connection refused")
+}
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: production
+clusters:
+- name: production
+  cluster:
+    server: https://production.example.com
+- name: staging
+  cluster:
+    server: https://staging.example.com
+contexts:
+- name: production
+  context:
+    cluster: production
+    user: ci
+- name: staging
+  context:
+    cluster: staging
+    user: ci
+users:
+- name: ci
+  user:
+    token: secret
+`
+
+func writeKubeconfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(path, []byte(testKubeconfig), 0600))
+	return path
+}
+
+func TestRestConfigKubeconfigContext(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	viper.Set(param.Kubeconfig, writeKubeconfig(t))
+
+	restConfig, err := RestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://production.example.com", restConfig.Host, "Expected the current context by default")
+	assert.Equal(t, "kube-monkey", restConfig.UserAgent)
+
+	viper.Set(param.KubeContext, "staging")
+	restConfig, err = RestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", restConfig.Host)
+
+	viper.Set(param.KubeContext, "unknown")
+	_, err = RestConfig()
+	assert.Error(t, err)
+}
+
+func TestRestConfigImpersonation(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	viper.Set(param.Kubeconfig, writeKubeconfig(t))
+	viper.Set(param.ImpersonateUser, "chaos")
+	viper.Set(param.ImpersonateGroups, []string{"chaos-engineers"})
+
+	restConfig, err := RestConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "chaos", restConfig.Impersonate.UserName)
+	assert.Equal(t, []string{"chaos-engineers"}, restConfig.Impersonate.Groups)
+}
+
+func TestNewClusterClientProviders(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	providers := NewClusterClientProviders()
+	assert.Len(t, providers, 1)
+	assert.Contains(t, providers, "")
+
+	path := writeKubeconfig(t)
+	viper.Set(param.Clusters, []map[string]interface{}{
+		{"name": "staging", "kubeconfig": path, "context": "staging"},
+		{"name": "production", "kubeconfig": path},
+	})
+
+	providers = NewClusterClientProviders()
+	assert.Len(t, providers, 2)
+
+	clientset, _, err := providers["staging"].Clients()
+	assert.NoError(t, err)
+	assert.Equal(t, "staging.example.com", clientset.Discovery().RESTClient().Get().URL().Host)
+
+	again, _, err := providers["staging"].Clients()
+	assert.NoError(t, err)
+	assert.Same(t, clientset, again, "Expected the clients to be reused")
+}
+
+func TestNewClusterClientProvidersIgnoreDefaultClusterOverrides(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	path := writeKubeconfig(t)
+	viper.Set(param.ClusterAPIServerHost, "https://proxy.example.com")
+	viper.Set(param.ImpersonateUser, "chaos")
+	viper.Set(param.Clusters, []map[string]interface{}{
+		{"name": "staging", "kubeconfig": path, "context": "staging"},
+		{"name": "production", "kubeconfig": path},
+	})
+
+	providers := NewClusterClientProviders()
+	for name, host := range map[string]string{"staging": "https://staging.example.com", "production": "https://production.example.com"} {
+		restConfig, err := providers[name].(*clientProvider).restConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, host, restConfig.Host, "Expected cluster %s to keep the host of its kubeconfig", name)
+		assert.Empty(t, restConfig.Impersonate.UserName, "Expected cluster %s not to be impersonated", name)
+	}
+
+	viper.Set(param.Kubeconfig, path)
+	restConfig, err := NewClientProvider().(*clientProvider).restConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://proxy.example.com", restConfig.Host, "Expected the host override to apply to the default cluster")
+	assert.Equal(t, "chaos", restConfig.Impersonate.UserName)
+}
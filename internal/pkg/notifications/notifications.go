@@ -1,8 +1,9 @@
 package notifications
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
 	"kube-monkey/internal/pkg/chaos"
@@ -34,6 +35,24 @@ func ReportSchedule(client Client, schedule *schedule.Schedule) bool {
 	return success
 }
 
+// ReportConfigRejected reports a change of the config file rejected
+// because of the error, while the previous config remains in effect
+func ReportConfigRejected(client Client, err error) bool {
+	success := true
+	receiver := config.NotificationsAttacks()
+
+	text, _ := json.Marshal(fmt.Sprintf("kube-monkey %s rejected a config change, keeping the previous config: %v", config.KubeMonkeyID(""), err))
+	msg := fmt.Sprintf("{\"text\": %s}", text)
+
+	glog.V(1).Infof("reporting rejected config change")
+	if err := Send(client, receiver.Endpoint, msg, toHeaders(receiver.Headers)); err != nil {
+		glog.Errorf("error reporting rejected config change")
+		success = false
+	}
+
+	return success
+}
+
 func ReportAttack(client Client, result *chaos.Result, time time.Time) bool {
 	success := true
 
@@ -42,7 +61,9 @@ func ReportAttack(client Client, result *chaos.Result, time time.Time) bool {
 	if result.Error() != nil {
 		errorString = result.Error().Error()
 	}
-	msg := ReplacePlaceholders(receiver.Message, result.Victim().Name(), result.Victim().Kind(), result.Victim().Namespace(), errorString, time, os.Getenv("KUBE_MONKEY_ID"))
+	msg := ReplacePlaceholders(receiver.Message, result.Victim().Name(), result.Victim().Kind(), result.Victim().Namespace(), errorString, time, config.KubeMonkeyID(result.Victim().Cluster()))
+	msg = strings.Replace(msg, Outcome, attackOutcome(result), -1)
+	msg = strings.Replace(msg, Recovery, attackRecovery(result.Recovery()), -1)
 	glog.V(1).Infof("reporting attack for %s %s to %s with message %s\n", result.Victim().Kind(), result.Victim().Name(), receiver.Endpoint, msg)
 	if err := Send(client, receiver.Endpoint, msg, toHeaders(receiver.Headers)); err != nil {
 		glog.Errorf("error reporting attack for %s %s to %s with message %s, error: %v\n", result.Victim().Kind(), result.Victim().Name(), receiver.Endpoint, msg, err)
@@ -51,3 +72,28 @@ func ReportAttack(client Client, result *chaos.Result, time time.Time) bool {
 
 	return success
 }
+
+// Returns whether the victim recovered from the attack, or an empty
+// string if its recovery was not verified
+func attackRecovery(recovery *chaos.Recovery) string {
+	switch {
+	case recovery == nil:
+		return ""
+	case recovery.Recovered:
+		return fmt.Sprintf("recovered in %s", recovery.Duration.Round(time.Second))
+	default:
+		return fmt.Sprintf("not recovered after %s: %v", recovery.Duration.Round(time.Second), recovery.Err)
+	}
+}
+
+// Returns whether the attack succeeded, was skipped or failed
+func attackOutcome(result *chaos.Result) string {
+	switch {
+	case result.Skipped():
+		return OutcomeSkipped
+	case result.Error() != nil:
+		return OutcomeFailed
+	default:
+		return OutcomeSucceeded
+	}
+}
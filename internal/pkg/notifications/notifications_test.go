@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/chaos"
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AttackOutcome(t *testing.T) {
+	c := chaos.NewMock()
+
+	assert.Equal(t, OutcomeSucceeded, attackOutcome(chaos.NewResult(c, nil)))
+	assert.Equal(t, OutcomeFailed, attackOutcome(chaos.NewResult(c, errors.New("failed"))))
+	assert.Equal(t, OutcomeSkipped, attackOutcome(chaos.NewResult(c, &victims.SkipError{Kind: "Pod", Name: "name", Reason: "is blacklisted"})))
+}
+
+func Test_AttackRecovery(t *testing.T) {
+	assert.Empty(t, attackRecovery(nil))
+	assert.Equal(t, "recovered in 14s", attackRecovery(&chaos.Recovery{Recovered: true, Duration: 14200 * time.Millisecond}))
+	assert.Equal(t, "not recovered after 10m0s: timed out", attackRecovery(&chaos.Recovery{Duration: 10 * time.Minute, Err: errors.New("timed out")}))
+}
+
+func TestReportConfigRejected(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	viper.Set(param.NotificationsAttacks, map[string]interface{}{"endpoint": server.URL})
+	defer viper.Set(param.NotificationsAttacks, config.Receiver{})
+
+	assert.True(t, ReportConfigRejected(CreateClient(nil), errors.New(`RunHour: "kubemonkey.run_hour" is invalid`)))
+
+	var msg map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(body), &msg))
+	assert.Contains(t, msg["text"], `rejected a config change, keeping the previous config: RunHour: "kubemonkey.run_hour" is invalid`)
+}
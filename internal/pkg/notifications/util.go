@@ -23,6 +23,13 @@ const (
 	Date         = "{$date}"
 	Error        = "{$error}"
 	KubeMonkeyID = "{$kubemonkeyid}"
+	Outcome      = "{$outcome}"
+	Recovery     = "{$recovery}"
+
+	// outcomes of an attack
+	OutcomeSucceeded = "succeeded"
+	OutcomeSkipped   = "skipped"
+	OutcomeFailed    = "failed"
 )
 
 func toHeaders(headersArray []string) map[string]string {
@@ -0,0 +1,237 @@
+/*
+Package plan simulates the schedule of the attacks without attacking
+
+A plan lists the victims kube-monkey would schedule if it generated
+its schedule now, with the kill mode read from their labels, and the
+enrolled k8s objects it would skip, with the reason they are skipped.
+*/
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/kubernetes"
+	"kube-monkey/internal/pkg/schedule"
+	"kube-monkey/internal/pkg/victims"
+	"kube-monkey/internal/pkg/victims/factory"
+)
+
+// TimeFormat is the format of the kill times in the table of a plan
+const TimeFormat = "15:04 MST"
+
+// Entry is an enrolled k8s object of the plan, which is either attacked
+// at its kill times or skipped
+type Entry struct {
+	Cluster   string      `json:"cluster,omitempty"`
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name,omitempty"`
+	Mtbf      string      `json:"mtbf,omitempty"`
+	KillType  string      `json:"killType,omitempty"`
+	KillValue string      `json:"killValue,omitempty"`
+	KillTimes []time.Time `json:"killTimes,omitempty"`
+
+	// Skipped is true if the object is not attacked today
+	Skipped bool `json:"skipped"`
+
+	// Reason is why the object is skipped, or why its attacks
+	// are expected to fail, e.g. because of an invalid kill mode
+	Reason string `json:"reason,omitempty"`
+}
+
+// Plan is the simulated schedule of the attacks of all clusters
+type Plan struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	DryRun      bool      `json:"dryRun"`
+	Entries     []Entry   `json:"entries"`
+}
+
+// New simulates the schedule of the attacks of the clusters
+// of the clients, keyed by the name of their cluster
+func New(clients map[string]kubernetes.ClientProvider) (*Plan, error) {
+	clusters := make([]string, 0, len(clients))
+	for cluster := range clients {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	now := time.Now()
+	plan := &Plan{
+		GeneratedAt: now,
+		DryRun:      config.DryRun(),
+		Entries:     []Entry{},
+	}
+	for _, cluster := range clusters {
+		entries, err := ForCluster(cluster, clients[cluster], now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan cluster %q: %v", cluster, err)
+		}
+		plan.Entries = append(plan.Entries, entries...)
+	}
+	return plan, nil
+}
+
+// ForCluster simulates the schedule of the attacks of the cluster
+// generated at now. The entries are sorted by namespace, kind and name
+func ForCluster(cluster string, clients kubernetes.ClientProvider, now time.Time) ([]Entry, error) {
+	var (
+		mu      sync.Mutex
+		skipped []victims.SkippedVictim
+	)
+	// The victims are skipped while they are listed and scheduled
+	remove := victims.OnSkip(func(victim victims.SkippedVictim) {
+		mu.Lock()
+		defer mu.Unlock()
+		skipped = append(skipped, victim)
+	})
+	eligible, err := factory.EligibleVictims(cluster, clients)
+	if err != nil {
+		remove()
+		return nil, err
+	}
+	sched := schedule.ForVictims(cluster, eligible, now)
+	remove()
+
+	clientset, dynamicClient, err := clients.Clients()
+	if err != nil {
+		return nil, err
+	}
+	client := victims.NewCachingVictimClient(victims.NewVictimClient(clientset, dynamicClient))
+
+	killTimes := map[victims.Victim][]time.Time{}
+	for _, entry := range sched.Entries() {
+		killTimes[entry.Victim()] = append(killTimes[entry.Victim()], entry.KillAt())
+	}
+
+	inCooldown := map[string]bool{}
+	entries := make([]Entry, 0, len(eligible)+len(skipped))
+	for _, victim := range skipped {
+		inCooldown[key(victim.Kind, victim.Namespace, victim.Name)] = true
+		entries = append(entries, Entry{
+			Cluster:   cluster,
+			Kind:      victim.Kind,
+			Namespace: victim.Namespace,
+			Name:      victim.Name,
+			Skipped:   true,
+			Reason:    victim.Reason,
+		})
+	}
+
+	for _, victim := range eligible {
+		if inCooldown[key(victim.Kind(), victim.Namespace(), victim.Name())] {
+			continue
+		}
+
+		entry := Entry{
+			Cluster:   cluster,
+			Kind:      victim.Kind(),
+			Namespace: victim.Namespace(),
+			Name:      victim.Name(),
+			Mtbf:      victim.Mtbf().String(),
+			KillTimes: killTimes[victim],
+		}
+		sort.Slice(entry.KillTimes, func(i, j int) bool { return entry.KillTimes[i].Before(entry.KillTimes[j]) })
+
+		entry.KillType, entry.KillValue, err = killMode(client, victim)
+		if err != nil {
+			entry.Reason = fmt.Sprintf("attacks will fail: %v", err)
+		}
+		if len(entry.KillTimes) == 0 {
+			entry.Skipped = true
+			entry.Reason = fmt.Sprintf("no attack drawn today for an mtbf of %s", entry.Mtbf)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return entries, nil
+}
+
+// Returns the kill type and value of the labels of the victim. The kill
+// value is empty if the kill type does not require one
+func killMode(client victims.VictimKubeClient, victim victims.Victim) (string, string, error) {
+	killType, err := victim.KillType(client)
+	if err != nil {
+		return "", "", err
+	}
+
+	if handler, ok := victim.(victims.KillModeHandler); ok && handler.HandlesKillMode(killType) {
+		return killType, "", nil
+	}
+	if killType == config.KillAllLabelValue {
+		return killType, "", nil
+	}
+
+	killValue, err := victim.KillValue(client)
+	if err != nil {
+		return killType, "", err
+	}
+	return killType, strconv.Itoa(killValue), nil
+}
+
+func key(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// Scheduled returns the number of attacks of the plan
+func (p *Plan) Scheduled() (attacks int) {
+	for _, entry := range p.Entries {
+		attacks += len(entry.KillTimes)
+	}
+	return
+}
+
+// WriteJSON writes the plan as indented JSON
+func (p *Plan) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// WriteTable writes the plan as a table, with a row per entry
+func (p *Plan) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	mode := "attacks"
+	if p.DryRun {
+		mode = "attacks in dry run mode"
+	}
+	fmt.Fprintf(tw, "%d %s planned for %s\n\n", p.Scheduled(), mode, p.GeneratedAt.In(config.Timezone()).Format("2006-01-02"))
+
+	fmt.Fprintln(tw, "CLUSTER\tKIND\tNAMESPACE\tNAME\tMTBF\tKILL TYPE\tKILL VALUE\tKILL TIMES\tREASON")
+	for _, entry := range p.Entries {
+		killTimes := make([]string, 0, len(entry.KillTimes))
+		for _, killTime := range entry.KillTimes {
+			killTimes = append(killTimes, killTime.In(config.Timezone()).Format(TimeFormat))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			orNone(entry.Cluster), entry.Kind, entry.Namespace, orNone(entry.Name), orNone(entry.Mtbf),
+			orNone(entry.KillType), orNone(entry.KillValue), orNone(strings.Join(killTimes, ",")), entry.Reason)
+	}
+	return tw.Flush()
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,102 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/kubernetes"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newDeployment(name string, labels map[string]string) *appsv1.Deployment {
+	enrolled := map[string]string{config.EnabledLabelKey: config.EnabledLabelValue}
+	for key, value := range labels {
+		enrolled[key] = value
+	}
+	return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", Labels: enrolled}}
+}
+
+func newClients() kubernetes.ClientProvider {
+	return kubernetes.NewStaticClientProvider(fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+		newDeployment("web", map[string]string{
+			config.MtbfLabelKey:      "1",
+			config.KillTypeLabelKey:  config.KillFixedLabelValue,
+			config.KillValueLabelKey: "2",
+		}),
+		newDeployment("api", map[string]string{config.MtbfLabelKey: "1"}),
+		newDeployment("batch", map[string]string{config.MtbfLabelKey: "often"}),
+	), nil)
+}
+
+func setup() {
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DisabledVictimKinds, []string{"clusters.postgresql.cnpg.io", "rollouts.argoproj.io"})
+}
+
+func TestForCluster(t *testing.T) {
+	setup()
+	defer viper.Reset()
+
+	entries, err := ForCluster("", newClients(), time.Now())
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	api, batch, web := entries[0], entries[1], entries[2]
+
+	assert.Equal(t, "api", api.Name)
+	assert.False(t, api.Skipped)
+	assert.Len(t, api.KillTimes, 1, "Expected an mtbf of 1 day to be attacked once")
+	assert.Contains(t, api.Reason, "attacks will fail")
+
+	assert.Equal(t, "batch", batch.Name)
+	assert.True(t, batch.Skipped)
+	assert.Empty(t, batch.KillTimes)
+	assert.NotEmpty(t, batch.Reason)
+
+	assert.Equal(t, "web", web.Name)
+	assert.Equal(t, "v1.Deployment", web.Kind)
+	assert.Equal(t, "app", web.Namespace)
+	assert.Equal(t, "24h0m0s", web.Mtbf)
+	assert.Equal(t, config.KillFixedLabelValue, web.KillType)
+	assert.Equal(t, "2", web.KillValue)
+	assert.Len(t, web.KillTimes, 1)
+	assert.False(t, web.Skipped)
+	assert.Empty(t, web.Reason)
+}
+
+func TestNew(t *testing.T) {
+	setup()
+	defer viper.Reset()
+
+	plan, err := New(map[string]kubernetes.ClientProvider{"": newClients()})
+	require.NoError(t, err)
+	assert.True(t, plan.DryRun)
+	assert.Len(t, plan.Entries, 3)
+	assert.Equal(t, 2, plan.Scheduled())
+
+	var table bytes.Buffer
+	require.NoError(t, plan.WriteTable(&table))
+	assert.Contains(t, table.String(), "2 attacks in dry run mode planned for")
+	assert.Contains(t, table.String(), "CLUSTER  KIND")
+	assert.Regexp(t, `-\s+v1.Deployment\s+app\s+web\s+24h0m0s\s+fixed\s+2\s+\d\d:\d\d`, table.String())
+
+	var decoded Plan
+	var out bytes.Buffer
+	require.NoError(t, plan.WriteJSON(&out))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, plan.Entries[2].Name, decoded.Entries[2].Name)
+	assert.Equal(t, plan.Entries[2].KillType, decoded.Entries[2].KillType)
+}
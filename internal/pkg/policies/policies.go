@@ -0,0 +1,285 @@
+/*
+Package policies merges the ChaosPolicy and ChaosTarget custom resources
+into the config read from the config file
+
+ChaosPolicy resources are cluster-scoped and set the windows, namespaces,
+notifications and safeguards of kube-monkey. They are merged in the order
+of their names, so a policy overrides the fields set by the policies before
+it. ChaosTarget resources are namespaced, and whitelist their namespace, or
+blacklist it while paused. The Accepted condition of the resources reports
+whether they were merged, or the validation error that rejected them.
+*/
+package policies
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/spf13/viper"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ResyncPeriod is the period at which the resources are merged again
+const ResyncPeriod = 10 * time.Minute
+
+// IsAvailable checks if the ChaosPolicy custom resource is served by the apiserver
+func IsAvailable(client dynamic.Interface) bool {
+	_, err := client.Resource(ChaosPolicyGVR).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	return err == nil
+}
+
+// Controller watches the ChaosPolicy and ChaosTarget resources
+// and merges them into the config
+type Controller struct {
+	client dynamic.Interface
+
+	// Serializes the merges triggered by the informers
+	mu sync.Mutex
+
+	policies cache.SharedIndexInformer
+	targets  cache.SharedIndexInformer
+}
+
+// NewController creates a controller of the resources served through the client
+func NewController(client dynamic.Interface) *Controller {
+	return &Controller{client: client}
+}
+
+// Start watches the resources and merges them into the config on every
+// change until stopCh is closed. It returns once the resources are merged
+func (c *Controller) Start(stopCh <-chan struct{}) error {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(c.client, ResyncPeriod)
+	c.policies = factory.ForResource(ChaosPolicyGVR).Informer()
+	c.targets = factory.ForResource(ChaosTargetGVR).Informer()
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { c.sync() },
+		UpdateFunc: func(interface{}, interface{}) { c.sync() },
+		DeleteFunc: func(interface{}) { c.sync() },
+	}
+	for _, informer := range []cache.SharedIndexInformer{c.policies, c.targets} {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return fmt.Errorf("failed to watch chaos policies: %v", err)
+		}
+	}
+
+	factory.Start(stopCh)
+	for gvr, synced := range factory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("failed to sync %s", gvr.GroupResource())
+		}
+	}
+	glog.V(1).Infof("Watching %s and %s", ChaosPolicyGVR.GroupResource(), ChaosTargetGVR.GroupResource())
+	c.sync()
+	return nil
+}
+
+// Merges the resources in the caches of the informers
+func (c *Controller) sync() {
+	c.Reconcile(unstructuredItems(c.policies.GetStore().List()), unstructuredItems(c.targets.GetStore().List()))
+}
+
+// Reconcile merges the policies and targets into the config, and
+// reports whether they were accepted in their status
+func (c *Controller) Reconcile(policies, targets []*unstructured.Unstructured) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sort.Slice(policies, func(i, j int) bool { return policies[i].GetName() < policies[j].GetName() })
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].GetNamespace() != targets[j].GetNamespace() {
+			return targets[i].GetNamespace() < targets[j].GetNamespace()
+		}
+		return targets[i].GetName() < targets[j].GetName()
+	})
+
+	// Each policy is validated aside, on top of the policies accepted
+	// before it, and the live config is only swapped once at the end
+	merged := map[string]interface{}{}
+	for _, policy := range policies {
+		var spec ChaosPolicySpec
+		err := specOf(policy, &spec)
+		if err == nil {
+			candidate := mergeValues(merged, spec.Overrides())
+			if _, err = config.WithOverrides(candidate); err == nil {
+				merged = candidate
+			}
+		}
+		if err != nil {
+			glog.Errorf("Rejected ChaosPolicy %s. Error: %v", policy.GetName(), err)
+		}
+		c.setAccepted(ChaosPolicyGVR, policy, err)
+	}
+
+	values, errs := map[string]interface{}{}, map[*unstructured.Unstructured]error{}
+	base, err := config.WithOverrides(merged)
+	if err == nil {
+		values, errs = targetValues(base, targets)
+		err = config.SetOverrides(mergeValues(merged, values))
+	}
+	if err != nil {
+		glog.Errorf("Rejected ChaosTargets. Error: %v", err)
+		// Keep the accepted policies without the targets
+		if mergeErr := config.SetOverrides(merged); mergeErr != nil {
+			glog.Errorf("Failed to merge chaos policies. Error: %v", mergeErr)
+		}
+	}
+	for _, target := range targets {
+		targetErr := errs[target]
+		if targetErr == nil {
+			targetErr = err
+		}
+		c.setAccepted(ChaosTargetGVR, target, targetErr)
+	}
+}
+
+// Returns the namespaces whitelisted and blacklisted by the targets,
+// on top of the namespaces of the base config, and the targets that
+// failed to parse
+func targetValues(base *viper.Viper, targets []*unstructured.Unstructured) (map[string]interface{}, map[*unstructured.Unstructured]error) {
+	errs := map[*unstructured.Unstructured]error{}
+	var whitelisted, blacklisted []string
+	for _, target := range targets {
+		var spec ChaosTargetSpec
+		if err := specOf(target, &spec); err != nil {
+			errs[target] = err
+			continue
+		}
+		if spec.Paused {
+			blacklisted = append(blacklisted, target.GetNamespace())
+		} else {
+			whitelisted = append(whitelisted, target.GetNamespace())
+		}
+	}
+
+	values := map[string]interface{}{}
+	// All namespaces are targeted already without a whitelist
+	if len(whitelisted) > 0 && config.WhitelistEnabledWith(base) {
+		values[param.WhitelistedNamespaces] = appendNamespaces(base.GetStringSlice(param.WhitelistedNamespaces), whitelisted)
+	}
+	if len(blacklisted) > 0 {
+		values[param.BlacklistedNamespaces] = appendNamespaces(base.GetStringSlice(param.BlacklistedNamespaces), blacklisted)
+	}
+	return values, errs
+}
+
+// Appends the namespaces to the list, dropping its "" entry
+func appendNamespaces(list, namespaces []string) []string {
+	var merged []string
+	for _, namespace := range list {
+		if namespace != metav1.NamespaceAll {
+			merged = append(merged, namespace)
+		}
+	}
+	return append(merged, namespaces...)
+}
+
+// Sets the Accepted condition of the resource, updating its
+// status only if the condition changed
+func (c *Controller) setAccepted(gvr schema.GroupVersionResource, obj *unstructured.Unstructured, err error) {
+	condition := metav1.Condition{
+		Type:               ConditionAccepted,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonValid,
+		Message:            "Merged into the config",
+		ObservedGeneration: obj.GetGeneration(),
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonInvalid
+		condition.Message = err.Error()
+	}
+
+	conditions := conditionsOf(obj)
+	if !meta.SetStatusCondition(&conditions, condition) {
+		return
+	}
+
+	items := make([]interface{}, 0, len(conditions))
+	for _, cond := range conditions {
+		item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&cond)
+		if err != nil {
+			glog.Errorf("Failed to convert condition of %s %s. Error: %v", gvr.Resource, obj.GetName(), err)
+			return
+		}
+		items = append(items, item)
+	}
+
+	updated := obj.DeepCopy()
+	if err := unstructured.SetNestedSlice(updated.Object, items, "status", "conditions"); err != nil {
+		glog.Errorf("Failed to set status of %s %s. Error: %v", gvr.Resource, obj.GetName(), err)
+		return
+	}
+
+	if _, err := c.client.Resource(gvr).Namespace(obj.GetNamespace()).UpdateStatus(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+		glog.Errorf("Failed to update status of %s %s. Error: %v", gvr.Resource, obj.GetName(), err)
+	}
+}
+
+// Returns the status conditions of the resource
+func conditionsOf(obj *unstructured.Unstructured) []metav1.Condition {
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+
+	var conditions []metav1.Condition
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var condition metav1.Condition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(fields, &condition); err != nil {
+			continue
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// Parses the spec of the resource
+func specOf(obj *unstructured.Unstructured, spec interface{}) error {
+	fields, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return fmt.Errorf("invalid spec: %v", err)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(fields, spec); err != nil {
+		return fmt.Errorf("invalid spec: %v", err)
+	}
+	return nil
+}
+
+// Returns the values overridden by next on top of values
+func mergeValues(values, next map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(values)+len(next))
+	for key, value := range values {
+		merged[key] = value
+	}
+	for key, value := range next {
+		merged[key] = value
+	}
+	return merged
+}
+
+func unstructuredItems(objs []interface{}) []*unstructured.Unstructured {
+	items := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if item, ok := obj.(*unstructured.Unstructured); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
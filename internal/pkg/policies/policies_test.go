@@ -0,0 +1,143 @@
+package policies
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newResource(kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetAPIVersion(Group + "/" + Version)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetGeneration(1)
+	return obj
+}
+
+func newFakeClient(objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		ChaosPolicyGVR: "ChaosPolicyList",
+		ChaosTargetGVR: "ChaosTargetList",
+	}, objs...)
+}
+
+func acceptedCondition(t *testing.T, client *fake.FakeDynamicClient, gvr schema.GroupVersionResource, namespace, name string) metav1.Condition {
+	obj, err := client.Resource(gvr).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	conditions := conditionsOf(obj)
+	require.Len(t, conditions, 1)
+	return conditions[0]
+}
+
+// Loads the config file with the contents, which the policies override
+func loadConfig(t *testing.T, contents string) {
+	viper.Reset()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	require.NoError(t, config.Load(path))
+	t.Cleanup(func() {
+		_ = config.SetOverrides(map[string]interface{}{})
+		viper.Reset()
+		config.SetDefaults()
+	})
+}
+
+func TestOverrides(t *testing.T) {
+	hour := 9
+	enabled := true
+	spec := ChaosPolicySpec{
+		StartHour:  &hour,
+		Namespaces: &NamespacesSpec{Blacklisted: []string{"prod"}},
+		Notifications: &NotificationsSpec{
+			Enabled: &enabled,
+			Attacks: &ReceiverSpec{Endpoint: "http://receiver"},
+		},
+	}
+
+	values := spec.Overrides()
+	assert.Equal(t, 9, values[param.StartHour])
+	assert.Equal(t, []string{"prod"}, values[param.BlacklistedNamespaces])
+	assert.Equal(t, true, values[param.NotificationsEnabled])
+	assert.Equal(t, "http://receiver", values[param.NotificationsAttacks].(map[string]interface{})["endpoint"])
+	assert.NotContains(t, values, param.DryRun)
+	assert.NotContains(t, values, param.EndHour)
+}
+
+func TestReconcile(t *testing.T) {
+	loadConfig(t, "[kubemonkey]\nwhitelisted_namespaces = [\"app\"]\n")
+
+	base := newResource("ChaosPolicy", "", "00-base", map[string]interface{}{"runHour": int64(6), "dryRun": false})
+	later := newResource("ChaosPolicy", "", "10-later", map[string]interface{}{"runHour": int64(7)})
+	invalid := newResource("ChaosPolicy", "", "20-invalid", map[string]interface{}{"startHour": int64(24)})
+	target := newResource("ChaosTarget", "checkout", "target", map[string]interface{}{})
+	paused := newResource("ChaosTarget", "payments", "target", map[string]interface{}{"paused": true})
+
+	client := newFakeClient(base, later, invalid, target, paused)
+	controller := NewController(client)
+	controller.Reconcile(
+		[]*unstructured.Unstructured{invalid, later, base},
+		[]*unstructured.Unstructured{paused, target},
+	)
+
+	assert.Equal(t, 7, config.RunHour(), "Expected the later policy to override the base policy")
+	assert.False(t, config.DryRun())
+	assert.Equal(t, 10, config.StartHour(), "Expected the invalid policy to be excluded")
+	assert.Equal(t, []string{"app", "checkout"}, config.WhitelistedNamespaces().List())
+	assert.Equal(t, []string{metav1.NamespaceSystem, "payments"}, config.BlacklistedNamespaces().List())
+
+	condition := acceptedCondition(t, client, ChaosPolicyGVR, "", "00-base")
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonValid, condition.Reason)
+	assert.Equal(t, int64(1), condition.ObservedGeneration)
+
+	condition = acceptedCondition(t, client, ChaosPolicyGVR, "", "20-invalid")
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonInvalid, condition.Reason)
+	assert.Contains(t, condition.Message, param.StartHour)
+
+	condition = acceptedCondition(t, client, ChaosTargetGVR, "payments", "target")
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+}
+
+func TestReconcileSkipsUnchangedStatus(t *testing.T) {
+	loadConfig(t, "")
+
+	policy := newResource("ChaosPolicy", "", "policy", map[string]interface{}{"runHour": int64(6)})
+	client := newFakeClient(policy)
+	controller := NewController(client)
+
+	controller.Reconcile([]*unstructured.Unstructured{policy}, nil)
+	assert.Len(t, client.Actions(), 1)
+
+	updated, err := client.Resource(ChaosPolicyGVR).Get(context.TODO(), "policy", metav1.GetOptions{})
+	require.NoError(t, err)
+	client.ClearActions()
+
+	controller.Reconcile([]*unstructured.Unstructured{updated}, nil)
+	assert.Empty(t, client.Actions(), "Expected no status update without a change")
+}
+
+func TestReconcileWithoutWhitelist(t *testing.T) {
+	loadConfig(t, "")
+
+	target := newResource("ChaosTarget", "checkout", "target", map[string]interface{}{})
+	controller := NewController(newFakeClient(target))
+	controller.Reconcile(nil, []*unstructured.Unstructured{target})
+
+	assert.False(t, config.WhitelistEnabled(), "Expected all namespaces to stay targeted")
+}
@@ -0,0 +1,148 @@
+package policies
+
+import (
+	"kube-monkey/internal/pkg/config/param"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Group is the API group of the kube-monkey custom resources
+	Group = "kubemonkey.io"
+
+	// Version is the API version of the kube-monkey custom resources
+	Version = "v1alpha1"
+
+	// ConditionAccepted reports whether a resource is merged into the config
+	ConditionAccepted = "Accepted"
+
+	// ReasonValid is the reason of an accepted resource
+	ReasonValid = "Valid"
+
+	// ReasonInvalid is the reason of a resource rejected by validation
+	ReasonInvalid = "InvalidConfig"
+)
+
+var (
+	// ChaosPolicyGVR identifies the cluster-scoped ChaosPolicy resources
+	ChaosPolicyGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "chaospolicies"}
+
+	// ChaosTargetGVR identifies the namespaced ChaosTarget resources
+	ChaosTargetGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "chaostargets"}
+)
+
+// ChaosPolicySpec is the spec of a ChaosPolicy. Unset fields leave the
+// config read from the config file unchanged
+type ChaosPolicySpec struct {
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// Window of the terminations
+	Timezone  string `json:"timezone,omitempty"`
+	RunHour   *int   `json:"runHour,omitempty"`
+	StartHour *int   `json:"startHour,omitempty"`
+	EndHour   *int   `json:"endHour,omitempty"`
+
+	Namespaces    *NamespacesSpec    `json:"namespaces,omitempty"`
+	Notifications *NotificationsSpec `json:"notifications,omitempty"`
+	Safeguards    *SafeguardsSpec    `json:"safeguards,omitempty"`
+}
+
+// NamespacesSpec selects the namespaces targeted by kube-monkey
+type NamespacesSpec struct {
+	Whitelisted       []string `json:"whitelisted,omitempty"`
+	Blacklisted       []string `json:"blacklisted,omitempty"`
+	WhitelistSelector string   `json:"whitelistSelector,omitempty"`
+	BlacklistSelector string   `json:"blacklistSelector,omitempty"`
+}
+
+// NotificationsSpec configures the notifications of the attacks
+type NotificationsSpec struct {
+	Enabled        *bool         `json:"enabled,omitempty"`
+	ReportSchedule *bool         `json:"reportSchedule,omitempty"`
+	Attacks        *ReceiverSpec `json:"attacks,omitempty"`
+}
+
+// ReceiverSpec is the receiver of the notifications
+type ReceiverSpec struct {
+	Endpoint string   `json:"endpoint"`
+	Message  string   `json:"message,omitempty"`
+	Headers  []string `json:"headers,omitempty"`
+}
+
+// SafeguardsSpec limits the impact of the attacks
+type SafeguardsSpec struct {
+	CooldownHours       *int     `json:"cooldownHours,omitempty"`
+	GracePeriodSec      *int     `json:"gracePeriodSec,omitempty"`
+	RecoveryTimeoutSec  *int     `json:"recoveryTimeoutSec,omitempty"`
+	PodSelectionPolicy  string   `json:"podSelectionPolicy,omitempty"`
+	DisabledVictimKinds []string `json:"disabledVictimKinds,omitempty"`
+}
+
+// ChaosTargetSpec is the spec of a ChaosTarget, which whitelists its
+// namespace, or blacklists it while paused
+type ChaosTargetSpec struct {
+	Paused bool `json:"paused,omitempty"`
+}
+
+// Overrides returns the config values set by the policy, keyed by param
+func (s ChaosPolicySpec) Overrides() map[string]interface{} {
+	values := map[string]interface{}{}
+	setBool(values, param.DryRun, s.DryRun)
+	setString(values, param.Timezone, s.Timezone)
+	setInt(values, param.RunHour, s.RunHour)
+	setInt(values, param.StartHour, s.StartHour)
+	setInt(values, param.EndHour, s.EndHour)
+
+	if ns := s.Namespaces; ns != nil {
+		setStrings(values, param.WhitelistedNamespaces, ns.Whitelisted)
+		setStrings(values, param.BlacklistedNamespaces, ns.Blacklisted)
+		setString(values, param.WhitelistedNamespaceSelector, ns.WhitelistSelector)
+		setString(values, param.BlacklistedNamespaceSelector, ns.BlacklistSelector)
+	}
+
+	if n := s.Notifications; n != nil {
+		setBool(values, param.NotificationsEnabled, n.Enabled)
+		setBool(values, param.NotificationsReportSchedule, n.ReportSchedule)
+		if n.Attacks != nil {
+			values[param.NotificationsAttacks] = map[string]interface{}{
+				"endpoint": n.Attacks.Endpoint,
+				"message":  n.Attacks.Message,
+				"headers":  n.Attacks.Headers,
+			}
+		}
+	}
+
+	if sg := s.Safeguards; sg != nil {
+		setInt(values, param.CooldownHours, sg.CooldownHours)
+		setInt(values, param.GracePeriodSec, sg.GracePeriodSec)
+		setInt(values, param.RecoveryTimeoutSec, sg.RecoveryTimeoutSec)
+		setString(values, param.PodSelectionPolicy, sg.PodSelectionPolicy)
+		setStrings(values, param.DisabledVictimKinds, sg.DisabledVictimKinds)
+	}
+
+	return values
+}
+
+func setBool(values map[string]interface{}, key string, value *bool) {
+	if value != nil {
+		values[key] = *value
+	}
+}
+
+func setInt(values map[string]interface{}, key string, value *int) {
+	if value != nil {
+		values[key] = *value
+	}
+}
+
+func setString(values map[string]interface{}, key string, value string) {
+	if value != "" {
+		values[key] = value
+	}
+}
+
+func setStrings(values map[string]interface{}, key string, value []string) {
+	if value != nil {
+		values[key] = value
+	}
+}
@@ -2,8 +2,8 @@ package schedule
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
-	"os"
 	"strings"
 	"time"
 
@@ -12,6 +12,8 @@ import (
 	"kube-monkey/internal/pkg/calendar"
 	"kube-monkey/internal/pkg/chaos"
 	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/kubernetes"
+	"kube-monkey/internal/pkg/victims"
 	"kube-monkey/internal/pkg/victims/factory"
 )
 
@@ -27,9 +29,16 @@ const (
 )
 
 type Schedule struct {
+	cluster string
 	entries []*chaos.Chaos
 }
 
+// Cluster returns the name of the cluster target of the schedule,
+// which is empty unless config.Clusters is set
+func (s *Schedule) Cluster() string {
+	return s.cluster
+}
+
 func (s *Schedule) Entries() []*chaos.Chaos {
 	return s.entries
 }
@@ -43,7 +52,7 @@ func (s *Schedule) String() string {
 
 	schedString = append(schedString, fmt.Sprint(Today))
 
-	kubeMonkeyID := os.Getenv("KUBE_MONKEY_ID")
+	kubeMonkeyID := config.KubeMonkeyID(s.cluster)
 	if kubeMonkeyID != "" {
 		schedString = append(schedString, fmt.Sprintf(KubeMonkeyID, kubeMonkeyID))
 	}
@@ -69,26 +78,40 @@ func (s Schedule) Print() {
 	}
 }
 
-func New() (*Schedule, error) {
+// New creates the schedule of terminations of the eligible victims
+// of the cluster found through the clients
+func New(cluster string, clients kubernetes.ClientProvider) (*Schedule, error) {
 	glog.V(3).Info("Status Update: Generating schedule for terminations")
-	victims, err := factory.EligibleVictims()
+	victims, err := factory.EligibleVictims(cluster, clients)
 	if err != nil {
 		return nil, err
 	}
 
+	return ForVictims(cluster, victims, time.Now()), nil
+}
+
+// ForVictims creates the schedule of terminations of the victims of the
+// cluster, skipping those in cooldown at now
+func ForVictims(cluster string, eligible []victims.Victim, now time.Time) *Schedule {
 	schedule := &Schedule{
+		cluster: cluster,
 		entries: []*chaos.Chaos{},
 	}
 
-	for _, victim := range victims {
-		killtime := CalculateKillTime()
+	for _, victim := range eligible {
+		if victim.IsInCooldown(now) {
+			lastKilled, _ := victim.LastKilled()
+			glog.V(4).Infof("Skipping %s %s as it was last attacked at %s and is in cooldown for %s", victim.Kind(), victim.Name(), lastKilled.Format(DateFormat), victim.Cooldown())
+			victims.ReportSkipped(victim.Kind(), victim.Namespace(), victim.Name(), fmt.Sprintf("in cooldown for %s since %s", victim.Cooldown(), lastKilled.Format(DateFormat)))
+			continue
+		}
 
-		if ShouldScheduleChaos(victim.Mtbf()) {
+		for _, killtime := range CalculateKillTimes(NumberOfKills(victim.Mtbf())) {
 			schedule.Add(chaos.New(killtime, victim))
 		}
 	}
 
-	return schedule, nil
+	return schedule
 }
 
 func CalculateKillTime() time.Time {
@@ -102,12 +125,41 @@ func CalculateKillTime() time.Time {
 	return calendar.RandomTimeInRange(config.StartHour(), config.EndHour(), loc)
 }
 
-func ShouldScheduleChaos(mtbf int) bool {
+// CalculateKillTimes returns n kill times spread across the day's
+// termination window
+func CalculateKillTimes(n int) []time.Time {
+	if config.DebugEnabled() && config.DebugScheduleImmediateKill() {
+		killtimes := make([]time.Time, 0, n)
+		for i := 0; i < n; i++ {
+			killtimes = append(killtimes, CalculateKillTime())
+		}
+		return killtimes
+	}
+	return calendar.RandomTimesInRange(config.StartHour(), config.EndHour(), n, config.Timezone())
+}
+
+// NumberOfKills returns how many terminations to schedule today for a victim
+// with the given mean time between failures. A victim with an mtbf of a day or
+// more expects calendar.Day / mtbf terminations per day. As terminations are only
+// scheduled in the termination window, a shorter mtbf is measured within the
+// window, and expects window / mtbf terminations but at least one per day.
+// The integer part is always scheduled and the fractional part is the
+// probability of scheduling one more
+func NumberOfKills(mtbf time.Duration) int {
+	expected := float64(calendar.Day) / float64(mtbf)
+	if mtbf < calendar.Day {
+		window := time.Duration(config.EndHour()-config.StartHour()) * time.Hour
+		expected = math.Max(1, float64(window)/float64(mtbf))
+	}
+
 	if config.DebugEnabled() && config.DebugForceShouldKill() {
-		return true
+		return int(math.Max(1, expected))
 	}
 
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	probability := 1 / float64(mtbf)
-	return probability > r.Float64()
+	kills := math.Floor(expected)
+	if expected-kills > r.Float64() {
+		kills++
+	}
+	return int(kills)
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"kube-monkey/internal/pkg/calendar"
 	"kube-monkey/internal/pkg/chaos"
 	"kube-monkey/internal/pkg/config/param"
 
@@ -66,6 +67,23 @@ func TestStringNoEntriesWithID(t *testing.T) {
 	os.Unsetenv("KUBE_MONKEY_ID")
 }
 
+func TestStringNoEntriesWithCluster(t *testing.T) {
+	os.Setenv("KUBE_MONKEY_ID", "TestingID")
+	defer os.Unsetenv("KUBE_MONKEY_ID")
+
+	s := &Schedule{cluster: "staging"}
+
+	schedString := []string{}
+	schedString = append(schedString, fmt.Sprint(Today))
+	schedString = append(schedString, fmt.Sprintf(KubeMonkeyID, "staging"))
+
+	schedString = append(schedString, fmt.Sprint(NoTermination))
+	schedString = append(schedString, fmt.Sprint(End))
+
+	assert.Equal(t, strings.Join(schedString, "\n"), s.String())
+	assert.Equal(t, "staging", s.Cluster())
+}
+
 func TestStringWithEntries(t *testing.T) {
 	s := newSchedule()
 	e1 := chaos.NewMock()
@@ -112,15 +130,52 @@ func TestCalculateKillTimeNow(t *testing.T) {
 	config.SetDefaults()
 }
 
-func TestShouldScheduleChaosNow(t *testing.T) {
+func TestCalculateKillTimes(t *testing.T) {
+	config.SetDefaults()
+	killtimes := CalculateKillTimes(3)
+
+	assert.Len(t, killtimes, 3)
+	for i := 1; i < len(killtimes); i++ {
+		assert.True(t, killtimes[i-1].Before(killtimes[i]), "Expected kill times to be spread across the window")
+	}
+	for _, killtime := range killtimes {
+		assert.True(t, killtime.Hour() >= config.StartHour() && killtime.Hour() < config.EndHour())
+	}
+	assert.Empty(t, CalculateKillTimes(0))
+}
+
+func TestNumberOfKillsNow(t *testing.T) {
 	config.SetDefaults()
 	viper.SetDefault(param.DebugEnabled, true)
 	viper.SetDefault(param.DebugForceShouldKill, true)
-	assert.True(t, ShouldScheduleChaos(100000000000))
+	assert.Equal(t, 1, NumberOfKills(100000*calendar.Day))
+	assert.Equal(t, 1, NumberOfKills(4*time.Hour))
+	assert.Equal(t, 6, NumberOfKills(time.Hour))
 	config.SetDefaults()
 }
 
-func TestShouldScheduleChaosMtbf(t *testing.T) {
-	assert.False(t, ShouldScheduleChaos(100000000000))
-	assert.True(t, ShouldScheduleChaos(1))
+func TestNumberOfKillsMtbf(t *testing.T) {
+	assert.Equal(t, 0, NumberOfKills(100000*calendar.Day))
+	assert.Equal(t, 1, NumberOfKills(calendar.Day))
+	assert.Equal(t, 1, NumberOfKills(16*time.Hour), "Expected at least one kill a day for an mtbf shorter than a day")
+	assert.Equal(t, 6, NumberOfKills(time.Hour))
+}
+
+func TestNumberOfKillsWindow(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	// The default window of 10:00 to 16:00 fits 1.5 mtbfs of 4h
+	for i := 0; i < 20; i++ {
+		kills := NumberOfKills(4 * time.Hour)
+		assert.True(t, kills == 1 || kills == 2, "Expected 1 or 2 kills for a 4h mtbf over the default window, got %d", kills)
+	}
+
+	viper.Set(param.StartHour, 8)
+	viper.Set(param.EndHour, 20)
+	assert.Equal(t, 3, NumberOfKills(4*time.Hour), "Expected the kills to follow the length of the window")
 }
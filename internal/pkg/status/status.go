@@ -0,0 +1,46 @@
+/*
+Package status serves the status of kube-monkey over HTTP
+
+GET /status returns the generation and validation status of the config
+file as JSON, e.g. to find out whether the last change of the config
+was rejected.
+*/
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config"
+)
+
+// Status is the status of kube-monkey served at /status
+type Status struct {
+	Config config.ReloadStatus `json:"config"`
+}
+
+// Handler serves the status of kube-monkey at /status
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(Status{Config: config.Status()}); err != nil {
+			glog.Errorf("Failed to write status. Error: %v", err)
+		}
+	})
+	return mux
+}
+
+// Serve serves the status of kube-monkey on the address until it fails
+func Serve(address string) error {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	glog.V(1).Infof("Serving status on %s", address)
+	return server.ListenAndServe()
+}
@@ -0,0 +1,198 @@
+package rollouts
+
+//All these functions require api access specific to the CRD
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/victims"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+var rolloutGVR = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Resource: "rollouts",
+	Version:  "v1alpha1",
+}
+
+// Phases of a Rollout in which a promotion is in progress
+var promotionPhases = map[string]bool{
+	"Progressing": true,
+	"Paused":      true,
+}
+
+// IsEligible checks if the Argo Rollout CRD is available in the cluster
+func IsEligible(dynamicClient dynamic.Interface) bool {
+	_, err := dynamicClient.Resource(rolloutGVR).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	return err == nil
+}
+
+// EligibleRollouts gets all eligible rollouts that opted in (filtered by config.EnabledLabel)
+func EligibleRollouts(dynamicClient dynamic.Interface, namespace string, filter *metav1.ListOptions) (eligVictims []victims.Victim, err error) {
+	unstructuredList, err := dynamicClient.Resource(rolloutGVR).Namespace(namespace).List(context.TODO(), *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range unstructuredList.Items {
+		itemCopy := item
+		victim, err := New(&itemCopy)
+		if err != nil {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", item.GetKind(), item.GetName(), err.Error())
+			victims.ReportSkipped(item.GetKind(), item.GetNamespace(), item.GetName(), err.Error())
+			continue
+		}
+
+		eligVictims = append(eligVictims, victim)
+	}
+
+	return
+}
+
+// Returns the rollout of the victim, looked up once per attack
+func (r *Rollout) get(client victims.VictimKubeClient) (*unstructured.Unstructured, error) {
+	return victims.Lookup(client, r, func() (*unstructured.Unstructured, error) {
+		return client.Dynamic().Resource(rolloutGVR).Namespace(r.Namespace()).Get(context.TODO(), r.Name(), metav1.GetOptions{})
+	})
+}
+
+/* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
+
+// IsEnrolled checks if the rollout is currently enrolled in kube-monkey
+func (r *Rollout) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return false, err
+	}
+	return r.EnrolledBy(obj.GetLabels()), nil
+}
+
+// UnhealthyReason returns why the rollout is not attacked if a canary or
+// blue-green promotion is in progress, as its pods are being replaced already
+func (r *Rollout) UnhealthyReason(client victims.VictimKubeClient) (string, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	if !victims.SkipsUnhealthy(obj.GetLabels()) {
+		return "", nil
+	}
+
+	if promoting, reason := isPromoting(obj); promoting {
+		return fmt.Sprintf("promotion in progress: %s", reason), nil
+	}
+
+	return "", nil
+}
+
+// KillType returns current killtype config label for update
+func (r *Rollout) KillType(client victims.VictimKubeClient) (string, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	return r.KillTypeFrom(obj.GetLabels())
+}
+
+// KillValue returns current killvalue config label for update
+func (r *Rollout) KillValue(client victims.VictimKubeClient) (int, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return -1, err
+	}
+
+	return r.KillValueFrom(obj.GetLabels())
+}
+
+// Annotate merges the annotations into the rollout
+func (r *Rollout) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(r, annotations, client.Dynamic().Resource(rolloutGVR).Namespace(r.Namespace()).Patch)
+}
+
+// Returns the pods of the rollout, found through its selector and verified
+// through the owner references Rollout -> ReplicaSet -> Pod
+func (r *Rollout) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSelector, found, err := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if err != nil || !found {
+		return nil, fmt.Errorf("%s %s does not have a valid spec.selector", r.Kind(), r.Name())
+	}
+	selector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawSelector, selector); err != nil {
+		return nil, err
+	}
+
+	listOpts, err := victims.ListOptionsForSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	replicasets, err := client.Kube().AppsV1().ReplicaSets(r.Namespace()).List(context.TODO(), *listOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var owners []types.UID
+	for _, rs := range replicasets.Items {
+		if controller := metav1.GetControllerOf(&rs); controller != nil && controller.UID == obj.GetUID() {
+			owners = append(owners, rs.UID)
+		}
+	}
+
+	return victims.PodsControlledBy(client, r.Namespace(), selector, owners...)
+}
+
+// Checks if a canary or blue-green promotion of the rollout is in progress,
+// i.e. the rollout is progressing or paused, or its current revision
+// has not become the stable revision yet
+func isPromoting(obj *unstructured.Unstructured) (bool, string) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if promotionPhases[phase] {
+		return true, "phase " + phase
+	}
+
+	currentPodHash, _, _ := unstructured.NestedString(obj.Object, "status", "currentPodHash")
+	stableRS, _, _ := unstructured.NestedString(obj.Object, "status", "stableRS")
+	if currentPodHash != "" && stableRS != "" && currentPodHash != stableRS {
+		return true, "revision " + currentPodHash + " is not stable yet"
+	}
+
+	return false, ""
+}
+
+// VerifyRecovery waits for the desired replicas of the rollout to be ready again
+func (r *Rollout) VerifyRecovery(client victims.VictimKubeClient, attackedAt time.Time, timeout time.Duration) error {
+	return victims.WaitForReadyReplicas(client, r, timeout, r.replicas)
+}
+
+// Returns the desired replicas of the rollout and its pods
+func (r *Rollout) replicas(client victims.VictimKubeClient) (int32, []corev1.Pod, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	desired := int64(1)
+	if replicas, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas"); err == nil && found {
+		desired = replicas
+	}
+	pods, err := r.pods(client)
+	return int32(desired), pods, err
+}
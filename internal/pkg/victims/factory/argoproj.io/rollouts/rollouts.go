@@ -0,0 +1,48 @@
+package rollouts
+
+import (
+	"fmt"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type Rollout struct {
+	*victims.VictimBase
+}
+
+// New creates a new instance of Rollout
+func New(obj *unstructured.Unstructured) (*Rollout, error) {
+	mtbf, err := meanTimeBetweenFailures(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	base := victims.New(obj.GetKind(), obj.GetName(), obj.GetNamespace(), identifier(obj), mtbf)
+	base.ReadHistory(obj)
+
+	victim := &Rollout{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
+}
+
+// The identifier label is optional, as the pods of a rollout are found
+// through its selector and verified through owner references
+func identifier(obj *unstructured.Unstructured) string {
+	return obj.GetLabels()[config.IdentLabelKey]
+}
+
+// Read the mean-time-between-failures value defined by the Rollout
+// in the label defined by config.MtbfLabelKey
+func meanTimeBetweenFailures(obj *unstructured.Unstructured) (time.Duration, error) {
+	mtbf, ok := obj.GetLabels()[config.MtbfLabelKey]
+	if !ok {
+		return -1, fmt.Errorf("%s %s does not have %s label", obj.GetKind(), obj.GetName(), config.MtbfLabelKey)
+	}
+
+	return victims.ParseMtbf(mtbf)
+}
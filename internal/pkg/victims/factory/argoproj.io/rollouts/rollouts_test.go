@@ -0,0 +1,149 @@
+package rollouts
+
+import (
+	"testing"
+
+	"kube-monkey/internal/pkg/calendar"
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	NAME      = "rollout_name"
+	NAMESPACE = metav1.NamespaceDefault
+	UID       = types.UID("rollout-uid")
+)
+
+func newRollout(labels map[string]string, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "web"},
+			},
+		},
+		"status": status,
+	}}
+	obj.SetAPIVersion("argoproj.io/v1alpha1")
+	obj.SetKind("Rollout")
+	obj.SetName(NAME)
+	obj.SetNamespace(NAMESPACE)
+	obj.SetUID(UID)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func newVictimClient(rollout *unstructured.Unstructured, objects ...runtime.Object) victims.VictimKubeClient {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{rolloutGVR: "RolloutList"}, rollout)
+	return victims.NewVictimClient(fake.NewSimpleClientset(objects...), dynamicClient)
+}
+
+func TestNew(t *testing.T) {
+	rollout := newRollout(map[string]string{config.MtbfLabelKey: "1"}, nil)
+
+	r, err := New(rollout)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Rollout", r.Kind())
+	assert.Equal(t, NAME, r.Name())
+	assert.Equal(t, NAMESPACE, r.Namespace())
+	assert.Equal(t, calendar.Day, r.Mtbf())
+}
+
+func TestEligibleRollouts(t *testing.T) {
+	rollout := newRollout(map[string]string{config.MtbfLabelKey: "1"}, nil)
+	client := newVictimClient(rollout)
+
+	victims, err := EligibleRollouts(client.Dynamic(), NAMESPACE, &metav1.ListOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, victims, 1)
+}
+
+func TestIsEnrolled(t *testing.T) {
+	labels := map[string]string{
+		config.MtbfLabelKey:    "1",
+		config.EnabledLabelKey: config.EnabledLabelValue,
+	}
+
+	rollout := newRollout(labels, map[string]interface{}{"phase": "Paused"})
+	r, _ := New(rollout)
+	b, err := r.IsEnrolled(newVictimClient(rollout))
+	assert.NoError(t, err)
+	assert.True(t, b, "Expected a rollout with a promotion in progress to remain enrolled")
+
+	rollout = newRollout(map[string]string{config.MtbfLabelKey: "1"}, nil)
+	b, err = r.IsEnrolled(newVictimClient(rollout))
+	assert.NoError(t, err)
+	assert.False(t, b)
+}
+
+func TestUnhealthyReason(t *testing.T) {
+	labels := map[string]string{
+		config.MtbfLabelKey:    "1",
+		config.EnabledLabelKey: config.EnabledLabelValue,
+	}
+
+	rollout := newRollout(labels, map[string]interface{}{"phase": "Healthy", "currentPodHash": "abc", "stableRS": "abc"})
+	r, _ := New(rollout)
+	reason, err := r.UnhealthyReason(newVictimClient(rollout))
+	assert.NoError(t, err)
+	assert.Empty(t, reason, "Expected healthy rollout to be attacked")
+
+	rollout = newRollout(labels, map[string]interface{}{"phase": "Paused"})
+	reason, err = r.UnhealthyReason(newVictimClient(rollout))
+	assert.NoError(t, err)
+	assert.Equal(t, "promotion in progress: phase Paused", reason)
+
+	rollout = newRollout(labels, map[string]interface{}{"phase": "Healthy", "currentPodHash": "def", "stableRS": "abc"})
+	reason, err = r.UnhealthyReason(newVictimClient(rollout))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, reason, "Expected a rollout with an unstable revision to be skipped")
+
+	labels[config.SkipUnhealthyLabelKey] = "false"
+	rollout = newRollout(labels, map[string]interface{}{"phase": "Paused"})
+	reason, err = r.UnhealthyReason(newVictimClient(rollout))
+	assert.NoError(t, err)
+	assert.Empty(t, reason, "Expected the rollout to be attacked when it does not skip when unhealthy")
+}
+
+func TestPods(t *testing.T) {
+	controller := true
+	rollout := newRollout(map[string]string{config.MtbfLabelKey: "1"}, nil)
+
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            NAME + "-abc",
+			Namespace:       NAMESPACE,
+			Labels:          map[string]string{"app": "web"},
+			UID:             "replicaset-uid",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Rollout", Name: NAME, UID: UID, Controller: &controller}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            NAME + "-abc-1",
+			Namespace:       NAMESPACE,
+			Labels:          map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: rs.Name, UID: rs.UID, Controller: &controller}},
+		},
+	}
+
+	r, _ := New(rollout)
+	pods, err := r.Pods(newVictimClient(rollout, rs, pod))
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, pod.Name, pods[0].Name)
+}
@@ -2,7 +2,8 @@ package clusters
 
 import (
 	"fmt"
-	"strconv"
+	"sync"
+	"time"
 
 	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
@@ -12,13 +13,14 @@ import (
 
 type Cluster struct {
 	*victims.VictimBase
+
+	// The state of the cluster before the last attack, to verify its recovery
+	mu     sync.Mutex
+	before *primaryState
 }
 
 func New(obj *unstructured.Unstructured) (*Cluster, error) {
-	ident, err := identifier(obj)
-	if err != nil {
-		return nil, err
-	}
+	ident := identifier(obj)
 	mtbf, err := meanTimeBetweenFailures(obj)
 	if err != nil {
 		return nil, err
@@ -28,33 +30,27 @@ func New(obj *unstructured.Unstructured) (*Cluster, error) {
 	name := obj.GetName()
 	namespace := obj.GetNamespace()
 
-	return &Cluster{VictimBase: victims.New(kind, name, namespace, ident, mtbf)}, nil
+	base := victims.New(kind, name, namespace, ident, mtbf)
+	base.ReadHistory(obj)
+
+	victim := &Cluster{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
 }
 
-func identifier(obj *unstructured.Unstructured) (string, error) {
-	labels := obj.GetLabels()
-	identifier, ok := labels[config.IdentLabelKey]
-	if !ok {
-		return "", fmt.Errorf("%s %s does not have %s label", obj.GetKind(), obj.GetName(), config.IdentLabelKey)
-	}
-	return identifier, nil
+// The identifier label is optional, as instance pods are found
+// through the cnpg.io/cluster label and owner references
+func identifier(obj *unstructured.Unstructured) string {
+	return obj.GetLabels()[config.IdentLabelKey]
 }
 
-func meanTimeBetweenFailures(obj *unstructured.Unstructured) (int, error) {
+func meanTimeBetweenFailures(obj *unstructured.Unstructured) (time.Duration, error) {
 	labels := obj.GetLabels()
 	mtbf, ok := labels[config.MtbfLabelKey]
 	if !ok {
 		return -1, fmt.Errorf("%s %s does not have %s label", obj.GetKind(), obj.GetName(), config.MtbfLabelKey)
 	}
 
-	mtbfInt, err := strconv.Atoi(mtbf)
-	if err != nil {
-		return -1, err
-	}
-
-	if !(mtbfInt > 0) {
-		return -1, fmt.Errorf("invalid value for label %s: %d", config.MtbfLabelKey, mtbfInt)
-	}
-
-	return mtbfInt, nil
+	return victims.ParseMtbf(mtbf)
 }
@@ -4,19 +4,21 @@ package clusters
 
 import (
 	"context"
-	"fmt"
-	"strconv"
 
 	"github.com/golang/glog"
 
-	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 )
 
+// clusterLabelKey is set by CNPG on the instance pods of a cluster
+const clusterLabelKey = "cnpg.io/cluster"
+
 var clusterGVR = schema.GroupVersionResource{
 	Group:    "postgresql.cnpg.io",
 	Resource: "clusters",
@@ -40,10 +42,7 @@ func EligibleClusters(dynamicClient dynamic.Interface, namespace string, filter
 		victim, err := New(&itemCopy)
 		if err != nil {
 			glog.Warningf("Skipping eligible %s %s because of error: %s", item.GetKind(), item.GetName(), err.Error())
-			continue
-		}
-
-		if victim.IsBlacklisted() {
+			victims.ReportSkipped(item.GetKind(), item.GetNamespace(), item.GetName(), err.Error())
 			continue
 		}
 
@@ -53,47 +52,57 @@ func EligibleClusters(dynamicClient dynamic.Interface, namespace string, filter
 	return
 }
 
+// Returns the cluster of the victim, looked up once per attack
+func (c *Cluster) get(client victims.VictimKubeClient) (*unstructured.Unstructured, error) {
+	return victims.Lookup(client, c, func() (*unstructured.Unstructured, error) {
+		return client.Dynamic().Resource(clusterGVR).Namespace(c.Namespace()).Get(context.TODO(), c.Name(), metav1.GetOptions{})
+	})
+}
+
 func (c *Cluster) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
-	obj, err := client.Dynamic().Resource(clusterGVR).Namespace(c.Namespace()).Get(context.TODO(), c.Name(), metav1.GetOptions{})
+	obj, err := c.get(client)
 	if err != nil {
 		return false, err
 	}
 
-	labels := obj.GetLabels()
-	return labels[config.EnabledLabelKey] == config.EnabledLabelValue, nil
+	return c.EnrolledBy(obj.GetLabels()), nil
 }
 
 func (c *Cluster) KillType(client victims.VictimKubeClient) (string, error) {
-	obj, err := client.Dynamic().Resource(clusterGVR).Namespace(c.Namespace()).Get(context.TODO(), c.Name(), metav1.GetOptions{})
+	obj, err := c.get(client)
 	if err != nil {
 		return "", err
 	}
 
-	labels := obj.GetLabels()
-	killType, ok := labels[config.KillTypeLabelKey]
-	if !ok {
-		return "", fmt.Errorf("%s %s does not have %s label", c.Kind(), c.Name(), config.KillTypeLabelKey)
-	}
-
-	return killType, nil
+	return c.KillTypeFrom(obj.GetLabels())
 }
 
 func (c *Cluster) KillValue(client victims.VictimKubeClient) (int, error) {
-	obj, err := client.Dynamic().Resource(clusterGVR).Namespace(c.Namespace()).Get(context.TODO(), c.Name(), metav1.GetOptions{})
+	obj, err := c.get(client)
 	if err != nil {
 		return -1, err
 	}
 
-	labels := obj.GetLabels()
-	killMode, ok := labels[config.KillValueLabelKey]
-	if !ok {
-		return -1, fmt.Errorf("%s %s does not have %s label", c.Kind(), c.Name(), config.KillValueLabelKey)
+	return c.KillValueFrom(obj.GetLabels())
+}
+
+func (c *Cluster) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(c, annotations, client.Dynamic().Resource(clusterGVR).Namespace(c.Namespace()).Patch)
+}
+
+// Returns the instance pods of the cluster targeted by TargetLabelKey, found
+// through the cnpg.io/cluster label and verified through their owner references
+func (c *Cluster) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	obj, err := c.get(client)
+	if err != nil {
+		return nil, err
 	}
 
-	killModeInt, err := strconv.Atoi(killMode)
-	if err != nil || !(killModeInt > 0) {
-		return -1, fmt.Errorf("Invalid value for label %s: %d", config.KillValueLabelKey, killModeInt)
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{clusterLabelKey: c.Name()}}
+	pods, err := victims.PodsControlledBy(client, c.Namespace(), selector, obj.GetUID())
+	if err != nil {
+		return nil, err
 	}
 
-	return killModeInt, nil
+	return targetedPods(pods, obj.GetLabels()[TargetLabelKey])
 }
@@ -0,0 +1,251 @@
+package clusters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// TargetLabelKey selects which instance pods of a cluster
+	// are candidates for termination
+	TargetLabelKey = "kube-monkey/cnpg-target"
+
+	// TargetPrimaryLabelValue only targets the primary instance
+	TargetPrimaryLabelValue = "primary"
+	// TargetReplicasLabelValue only targets the replica instances
+	TargetReplicasLabelValue = "replicas"
+	// TargetAllLabelValue targets all instances, the default
+	TargetAllLabelValue = "all"
+
+	// KillSwitchoverLabelValue is a kill mode requesting a planned
+	// switchover to a random replica instead of terminating pods
+	KillSwitchoverLabelValue = "switchover"
+
+	// instanceRoleLabelKey is set by CNPG on instance pods to their role
+	instanceRoleLabelKey = "cnpg.io/instanceRole"
+	primaryRole          = "primary"
+	replicaRole          = "replica"
+
+	// Cluster phases set by CNPG in status.phase
+	phaseHealthy    = "Cluster in healthy state"
+	phaseSwitchover = "Switchover in progress"
+)
+
+// PollInterval is the interval at which the status of a cluster
+// is checked while waiting for it to recover
+var PollInterval = 5 * time.Second
+
+// Filters the instance pods by the role targeted by the cluster
+func targetedPods(pods []corev1.Pod, target string) ([]corev1.Pod, error) {
+	var role string
+	switch target {
+	case "", TargetAllLabelValue:
+		return pods, nil
+	case TargetPrimaryLabelValue:
+		role = primaryRole
+	case TargetReplicasLabelValue:
+		role = replicaRole
+	default:
+		return nil, fmt.Errorf("Invalid value for label %s: %s", TargetLabelKey, target)
+	}
+
+	var targeted []corev1.Pod
+	for _, pod := range pods {
+		if pod.Labels[instanceRoleLabelKey] == role {
+			targeted = append(targeted, pod)
+		}
+	}
+	return targeted, nil
+}
+
+// HandlesKillMode checks if the kill mode is specific to CNPG clusters
+func (c *Cluster) HandlesKillMode(killType string) bool {
+	return killType == KillSwitchoverLabelValue
+}
+
+// Kill executes a kill mode specific to CNPG clusters. A switchover
+// terminates no pods
+func (c *Cluster) Kill(client victims.VictimKubeClient, killType string, _ int) (int, error) {
+	switch killType {
+	case KillSwitchoverLabelValue:
+		return 0, c.switchover(client)
+	default:
+		return 0, fmt.Errorf("failed to recognize KillType label for %s %s", c.Kind(), c.Name())
+	}
+}
+
+// Requests a planned switchover to a random ready replica, the same
+// way the cnpg kubectl plugin promotes an instance, by setting the
+// target primary in the status of the cluster
+func (c *Cluster) switchover(client victims.VictimKubeClient) error {
+	obj, err := c.get(client)
+	if err != nil {
+		return err
+	}
+
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if phase != phaseHealthy {
+		return &victims.SkipError{Kind: c.Kind(), Name: c.Name(), Reason: fmt.Sprintf("is not healthy (%s)", phase)}
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{clusterLabelKey: c.Name()}}
+	pods, err := victims.PodsControlledBy(client, c.Namespace(), selector, obj.GetUID())
+	if err != nil {
+		return err
+	}
+
+	var candidates []string
+	for _, pod := range pods {
+		if pod.Labels[instanceRoleLabelKey] == replicaRole && victims.IsReady(pod) {
+			candidates = append(candidates, pod.Name)
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("%s %s has no ready replica to switch over to", c.Kind(), c.Name())
+	}
+	target := candidates[rand.Intn(len(candidates))]
+
+	if config.DryRun() {
+		glog.Infof("[DryRun Mode] Requested switchover to %s for %s/%s", target, c.Namespace(), c.Name())
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"targetPrimary":          target,
+			"targetPrimaryTimestamp": time.Now().UTC().Format(metav1.RFC3339Micro),
+			"phase":                  phaseSwitchover,
+			"phaseReason":            fmt.Sprintf("Switching over to %s", target),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	glog.V(2).Infof("Requesting switchover to %s for %s/%s", target, c.Namespace(), c.Name())
+	_, err = client.Dynamic().Resource(clusterGVR).Namespace(c.Namespace()).Patch(context.TODO(), c.Name(), types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	return err
+}
+
+// The primary and instance pods of a cluster before an attack
+type primaryState struct {
+	currentPrimary         string
+	targetPrimaryTimestamp time.Time
+	primaryUID             types.UID
+	instances              sets.Set[string]
+}
+
+// Reads the primary of the cluster from its status, and its instances from the pods
+func readPrimaryState(obj *unstructured.Unstructured, pods []corev1.Pod) *primaryState {
+	state := &primaryState{instances: sets.New[string]()}
+	state.currentPrimary, _, _ = unstructured.NestedString(obj.Object, "status", "currentPrimary")
+
+	// The timestamp is the time the last switchover or failover was requested
+	timestamp, _, _ := unstructured.NestedString(obj.Object, "status", "targetPrimaryTimestamp")
+	state.targetPrimaryTimestamp, _ = time.Parse(time.RFC3339, timestamp)
+
+	for _, pod := range pods {
+		state.instances.Insert(pod.Name)
+		if pod.Name == state.currentPrimary {
+			state.primaryUID = pod.UID
+		}
+	}
+	return state
+}
+
+// RecordState records the primary and the instance pods of the cluster
+// before it is attacked, which VerifyRecovery compares to after the attack
+func (c *Cluster) RecordState(client victims.VictimKubeClient) error {
+	obj, err := c.get(client)
+	if err != nil {
+		return err
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{clusterLabelKey: c.Name()}}
+	pods, err := victims.PodsControlledBy(client, c.Namespace(), selector, obj.GetUID())
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.before = readPrimaryState(obj, pods)
+	return nil
+}
+
+// VerifyRecovery waits for the cluster to be healthy with its target
+// primary elected as the current primary, and its instance pods from
+// before the attack running again and ready. If the attack requested a
+// switchover or terminated the primary, a new primary must have been
+// elected since, i.e. the current primary or its timestamp changed
+func (c *Cluster) VerifyRecovery(client victims.VictimKubeClient, attackedAt time.Time, timeout time.Duration) error {
+	c.mu.Lock()
+	before := c.before
+	c.before = nil
+	c.mu.Unlock()
+	if before == nil {
+		return fmt.Errorf("%s %s has no state recorded before the attack of %s", c.Kind(), c.Name(), attackedAt.Format(time.RFC3339))
+	}
+
+	var phase, currentPrimary, targetPrimary string
+	err := wait.PollUntilContextTimeout(context.TODO(), PollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Dynamic().Resource(clusterGVR).Namespace(c.Namespace()).Get(ctx, c.Name(), metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		phase, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+		currentPrimary, _, _ = unstructured.NestedString(obj.Object, "status", "currentPrimary")
+		targetPrimary, _, _ = unstructured.NestedString(obj.Object, "status", "targetPrimary")
+		if phase != phaseHealthy || currentPrimary == "" || currentPrimary != targetPrimary {
+			return false, nil
+		}
+
+		selector := &metav1.LabelSelector{MatchLabels: map[string]string{clusterLabelKey: c.Name()}}
+		pods, err := victims.PodsControlledBy(client, c.Namespace(), selector, obj.GetUID())
+		if err != nil {
+			return false, err
+		}
+		if !instancesRecovered(pods, before.instances) {
+			return false, nil
+		}
+
+		after := readPrimaryState(obj, pods)
+		// A terminated primary only recovers once a new primary is elected
+		elected := after.currentPrimary != before.currentPrimary || after.targetPrimaryTimestamp.After(before.targetPrimaryTimestamp)
+		return elected || after.primaryUID == before.primaryUID, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s did not recover within %s (phase: %s, current primary: %s, target primary: %s): %v", c.Kind(), c.Name(), timeout, phase, currentPrimary, targetPrimary, err)
+	}
+
+	glog.V(3).Infof("%s %s recovered with primary %s", c.Kind(), c.Name(), currentPrimary)
+	return nil
+}
+
+// Checks if the instances are running and ready, and none of them is terminating
+func instancesRecovered(pods []corev1.Pod, instances sets.Set[string]) bool {
+	recovered := sets.New[string]()
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || !victims.IsReady(pod) {
+			return false
+		}
+		recovered.Insert(pod.Name)
+	}
+	return recovered.IsSuperset(instances)
+}
@@ -0,0 +1,241 @@
+package clusters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	NAME      = "cluster_name"
+	NAMESPACE = metav1.NamespaceDefault
+	UID       = types.UID("cluster-uid")
+)
+
+func newCluster(labels map[string]string, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": status,
+	}}
+	obj.SetAPIVersion("postgresql.cnpg.io/v1")
+	obj.SetKind("Cluster")
+	obj.SetName(NAME)
+	obj.SetNamespace(NAMESPACE)
+	obj.SetUID(UID)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func newInstance(name, role string, ready bool) *corev1.Pod {
+	controller := true
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: NAMESPACE,
+			Labels: map[string]string{
+				clusterLabelKey:      NAME,
+				instanceRoleLabelKey: role,
+			},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Cluster", Name: NAME, UID: UID, Controller: &controller}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func newVictimClient(cluster *unstructured.Unstructured, objects ...runtime.Object) victims.VictimKubeClient {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{clusterGVR: "ClusterList"}, cluster)
+	return victims.NewVictimClient(fake.NewSimpleClientset(objects...), dynamicClient)
+}
+
+func TestPodsTarget(t *testing.T) {
+	instances := []runtime.Object{
+		newInstance(NAME+"-1", primaryRole, true),
+		newInstance(NAME+"-2", replicaRole, true),
+		newInstance(NAME+"-3", replicaRole, true),
+	}
+
+	cases := map[string]int{
+		"":                       3,
+		TargetAllLabelValue:      3,
+		TargetPrimaryLabelValue:  1,
+		TargetReplicasLabelValue: 2,
+	}
+	for target, expected := range cases {
+		labels := map[string]string{config.MtbfLabelKey: "1", TargetLabelKey: target}
+		cluster := newCluster(labels, nil)
+		c, _ := New(cluster)
+
+		pods, err := c.Pods(newVictimClient(cluster, instances...))
+
+		assert.NoError(t, err)
+		assert.Lenf(t, pods, expected, "Unexpected number of pods for target %q", target)
+	}
+
+	cluster := newCluster(map[string]string{config.MtbfLabelKey: "1", TargetLabelKey: "leader"}, nil)
+	c, _ := New(cluster)
+
+	_, err := c.Pods(newVictimClient(cluster, instances...))
+
+	assert.EqualError(t, err, "Invalid value for label "+TargetLabelKey+": leader")
+}
+
+func TestSwitchover(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DryRun, false)
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	cluster := newCluster(map[string]string{config.MtbfLabelKey: "1"}, map[string]interface{}{
+		"phase":          phaseHealthy,
+		"currentPrimary": NAME + "-1",
+		"targetPrimary":  NAME + "-1",
+	})
+	c, _ := New(cluster)
+	client := newVictimClient(cluster,
+		newInstance(NAME+"-1", primaryRole, true),
+		newInstance(NAME+"-2", replicaRole, true),
+		newInstance(NAME+"-3", replicaRole, false),
+	)
+
+	assert.True(t, c.HandlesKillMode(KillSwitchoverLabelValue))
+	assert.False(t, c.HandlesKillMode(config.KillAllLabelValue))
+
+	killed, err := c.Kill(client, KillSwitchoverLabelValue, 0)
+	assert.NoError(t, err)
+	assert.Zero(t, killed, "Expected a switchover to terminate no pods")
+
+	obj, _ := client.Dynamic().Resource(clusterGVR).Namespace(NAMESPACE).Get(context.TODO(), NAME, metav1.GetOptions{})
+	targetPrimary, _, _ := unstructured.NestedString(obj.Object, "status", "targetPrimary")
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	assert.Equal(t, NAME+"-2", targetPrimary, "Expected the only ready replica to be the target primary")
+	assert.Equal(t, phaseSwitchover, phase)
+}
+
+func TestSwitchoverUnhealthy(t *testing.T) {
+	cluster := newCluster(map[string]string{config.MtbfLabelKey: "1"}, map[string]interface{}{
+		"phase": "Failing over",
+	})
+	c, _ := New(cluster)
+
+	_, err := c.Kill(newVictimClient(cluster, newInstance(NAME+"-2", replicaRole, true)), KillSwitchoverLabelValue, 0)
+
+	var skipErr *victims.SkipError
+	assert.ErrorAs(t, err, &skipErr, "Expected an unhealthy cluster to be skipped")
+	assert.EqualError(t, err, "Cluster "+NAME+" is not healthy (Failing over). Skipping")
+}
+
+func TestSwitchoverWithoutReplica(t *testing.T) {
+	cluster := newCluster(map[string]string{config.MtbfLabelKey: "1"}, map[string]interface{}{
+		"phase": phaseHealthy,
+	})
+	c, _ := New(cluster)
+
+	_, err := c.Kill(newVictimClient(cluster, newInstance(NAME+"-1", primaryRole, true)), KillSwitchoverLabelValue, 0)
+
+	assert.EqualError(t, err, "Cluster "+NAME+" has no ready replica to switch over to")
+}
+
+// Sets the current and target primary of the cluster
+func setPrimary(t *testing.T, client victims.VictimKubeClient, primary, timestamp string) {
+	obj, err := client.Dynamic().Resource(clusterGVR).Namespace(NAMESPACE).Get(context.TODO(), NAME, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedField(obj.Object, primary, "status", "currentPrimary"))
+	require.NoError(t, unstructured.SetNestedField(obj.Object, primary, "status", "targetPrimary"))
+	require.NoError(t, unstructured.SetNestedField(obj.Object, timestamp, "status", "targetPrimaryTimestamp"))
+	_, err = client.Dynamic().Resource(clusterGVR).Namespace(NAMESPACE).Update(context.TODO(), obj, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+// Replaces the instance pod with a new pod of the same name, as CNPG does
+func recreateInstance(t *testing.T, client victims.VictimKubeClient, name, role string) {
+	require.NoError(t, client.Kube().CoreV1().Pods(NAMESPACE).Delete(context.TODO(), name, metav1.DeleteOptions{}))
+	pod := newInstance(name, role, true)
+	pod.UID = types.UID(name + "-recreated")
+	_, err := client.Kube().CoreV1().Pods(NAMESPACE).Create(context.TODO(), pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func TestVerifyRecovery(t *testing.T) {
+	pollInterval := PollInterval
+	t.Cleanup(func() { PollInterval = pollInterval })
+	PollInterval = time.Millisecond
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DryRun, false)
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	const before = "2024-01-01T10:00:00.000000Z"
+	newClient := func() (*Cluster, victims.VictimKubeClient) {
+		cluster := newCluster(map[string]string{config.MtbfLabelKey: "1"}, map[string]interface{}{
+			"phase":                  phaseHealthy,
+			"currentPrimary":         NAME + "-1",
+			"targetPrimary":          NAME + "-1",
+			"targetPrimaryTimestamp": before,
+		})
+		c, _ := New(cluster)
+		client := newVictimClient(cluster,
+			newInstance(NAME+"-1", primaryRole, true),
+			newInstance(NAME+"-2", replicaRole, true),
+		)
+		require.NoError(t, c.RecordState(client))
+		return c, client
+	}
+
+	c, client := newClient()
+	recreateInstance(t, client, NAME+"-2", replicaRole)
+	assert.NoError(t, c.VerifyRecovery(client, time.Now(), time.Second), "Expected the primary to be kept after a replica was terminated")
+
+	c, client = newClient()
+	recreateInstance(t, client, NAME+"-1", primaryRole)
+	assert.Error(t, c.VerifyRecovery(client, time.Now(), 10*time.Millisecond), "Expected an error if no primary is elected after the primary was terminated")
+
+	c, client = newClient()
+	recreateInstance(t, client, NAME+"-1", replicaRole)
+	setPrimary(t, client, NAME+"-2", "2024-01-01T10:05:00.000000Z")
+	assert.NoError(t, c.VerifyRecovery(client, time.Now(), time.Second), "Expected a failover to recover the cluster")
+
+	c, client = newClient()
+	_, err := c.Kill(client, KillSwitchoverLabelValue, 0)
+	require.NoError(t, err)
+	assert.Error(t, c.VerifyRecovery(client, time.Now(), 10*time.Millisecond), "Expected an error if the target primary is not elected in time")
+
+	c, client = newClient()
+	pod, _ := client.Kube().CoreV1().Pods(NAMESPACE).Get(context.TODO(), NAME+"-2", metav1.GetOptions{})
+	pod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	_, err = client.Kube().CoreV1().Pods(NAMESPACE).Update(context.TODO(), pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Error(t, c.VerifyRecovery(client, time.Now(), 10*time.Millisecond), "Expected an error while an instance is terminating")
+
+	c, client = newClient()
+	require.NoError(t, c.VerifyRecovery(client, time.Now(), time.Second))
+	assert.Error(t, c.VerifyRecovery(client, time.Now(), time.Second), "Expected an error without a state recorded before the attack")
+}
@@ -0,0 +1,54 @@
+package cronjobs
+
+import (
+	"fmt"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+type CronJob struct {
+	*victims.VictimBase
+}
+
+// Kind of the cronjobs, i.e. v1.CronJob
+var kind = fmt.Sprintf("%T", batchv1.CronJob{})
+
+// New creates a new instance of CronJob
+func New(cj *batchv1.CronJob) (*CronJob, error) {
+	ident := identifier(cj)
+	mtbf, err := meanTimeBetweenFailures(cj)
+	if err != nil {
+		return nil, err
+	}
+
+	base := victims.New(kind, cj.Name, cj.Namespace, ident, mtbf)
+	base.ReadHistory(cj)
+
+	victim := &CronJob{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
+}
+
+// Returns the value of the label defined by config.IdentLabelKey
+// from the cronjob labels, if any
+// The label is optional, as the pods that belong to this cronjob
+// are found through its active jobs
+func identifier(kubekind *batchv1.CronJob) string {
+	return kubekind.Labels[config.IdentLabelKey]
+}
+
+// Read the mean-time-between-failures value defined by the CronJob
+// in the label defined by config.MtbfLabelKey
+func meanTimeBetweenFailures(kubekind *batchv1.CronJob) (time.Duration, error) {
+	mtbf, ok := kubekind.Labels[config.MtbfLabelKey]
+	if !ok {
+		return -1, fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.MtbfLabelKey)
+	}
+
+	return victims.ParseMtbf(mtbf)
+}
@@ -0,0 +1,121 @@
+package cronjobs
+
+import (
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/calendar"
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+	"kube-monkey/internal/pkg/victims/factory/jobs"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	NAME      = "cronjob_name"
+	NAMESPACE = metav1.NamespaceDefault
+	UID       = types.UID("cronjob-uid")
+)
+
+func newCronJob(labels map[string]string) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NAME,
+			Namespace: NAMESPACE,
+			Labels:    labels,
+			UID:       UID,
+		},
+	}
+}
+
+func newJob(name string, started time.Time, conditions ...batchv1.JobCondition) *batchv1.Job {
+	controller := true
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       NAMESPACE,
+			UID:             types.UID(name + "-uid"),
+			OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: NAME, UID: UID, Controller: &controller}},
+		},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": name}},
+		},
+		Status: batchv1.JobStatus{
+			StartTime:  &metav1.Time{Time: started},
+			Conditions: conditions,
+		},
+	}
+}
+
+func newPod(job *batchv1.Job) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            job.Name + "-1",
+			Namespace:       NAMESPACE,
+			Labels:          job.Spec.Selector.MatchLabels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: job.Name, UID: job.UID, Controller: &controller}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestNew(t *testing.T) {
+	cj, err := New(newCronJob(map[string]string{config.MtbfLabelKey: "1"}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.CronJob", cj.Kind())
+	assert.Equal(t, NAME, cj.Name())
+	assert.Equal(t, NAMESPACE, cj.Namespace())
+	assert.Equal(t, calendar.Day, cj.Mtbf())
+}
+
+func TestEligibleCronJobs(t *testing.T) {
+	client := fake.NewSimpleClientset(newCronJob(map[string]string{config.MtbfLabelKey: "1"}))
+	victims, _ := EligibleCronJobs(client, NAMESPACE, &metav1.ListOptions{})
+
+	assert.Len(t, victims, 1)
+}
+
+func TestPods(t *testing.T) {
+	v1cj := newCronJob(map[string]string{config.MtbfLabelKey: "1"})
+	active := newJob("active", time.Now())
+	finished := newJob("finished", time.Now(), batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue})
+
+	cj, _ := New(v1cj)
+	client := victims.NewVictimClient(fake.NewSimpleClientset(v1cj, active, finished, newPod(active), newPod(finished)), nil)
+	pods, err := cj.Pods(client)
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, "active-1", pods[0].Name)
+}
+
+func TestVerifyRecovery(t *testing.T) {
+	pollInterval := jobs.PollInterval
+	t.Cleanup(func() { jobs.PollInterval = pollInterval })
+	jobs.PollInterval = time.Millisecond
+	attackedAt := time.Now()
+	before := metav1.NewTime(attackedAt.Add(-time.Hour))
+	after := metav1.NewTime(attackedAt.Add(time.Minute))
+
+	v1cj := newCronJob(map[string]string{config.MtbfLabelKey: "1"})
+	cj, _ := New(v1cj)
+
+	// Failed before the attack, so it was not attacked
+	old := newJob("old", attackedAt.Add(-2*time.Hour), batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: before})
+	attacked := newJob("attacked", attackedAt.Add(-time.Minute), batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: after})
+
+	client := victims.NewVictimClient(fake.NewSimpleClientset(v1cj, old, attacked), nil)
+	assert.NoError(t, cj.VerifyRecovery(client, attackedAt, time.Second))
+
+	attacked = newJob("attacked", attackedAt.Add(-time.Minute), batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: after})
+	client = victims.NewVictimClient(fake.NewSimpleClientset(v1cj, old, attacked), nil)
+	assert.EqualError(t, cj.VerifyRecovery(client, attackedAt, time.Second), "v1.CronJob "+NAME+" jobs failed: attacked")
+}
@@ -0,0 +1,182 @@
+package cronjobs
+
+//All these functions require api access specific to the version of the app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/victims"
+	"kube-monkey/internal/pkg/victims/factory/jobs"
+
+	kube "k8s.io/client-go/kubernetes"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// EligibleCronJobs gets all eligible cronjobs that opted in (filtered by config.EnabledLabel)
+func EligibleCronJobs(clientset kube.Interface, namespace string, filter *metav1.ListOptions) (eligVictims []victims.Victim, err error) {
+	enabledVictims, err := clientset.BatchV1().CronJobs(namespace).List(context.TODO(), *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vic := range enabledVictims.Items {
+		victim, err := New(&vic)
+		if err != nil {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", kind, vic.Name, err.Error())
+			victims.ReportSkipped(kind, vic.Namespace, vic.Name, err.Error())
+			continue
+		}
+
+		eligVictims = append(eligVictims, victim)
+	}
+
+	return
+}
+
+// Returns the cronjob of the victim, looked up once per attack
+func (cj *CronJob) get(client victims.VictimKubeClient) (*batchv1.CronJob, error) {
+	return victims.Lookup(client, cj, func() (*batchv1.CronJob, error) {
+		return client.Kube().BatchV1().CronJobs(cj.Namespace()).Get(context.TODO(), cj.Name(), metav1.GetOptions{})
+	})
+}
+
+/* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
+
+// IsEnrolled checks if the cronjob is currently enrolled in kube-monkey
+func (cj *CronJob) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
+	cronjob, err := cj.get(client)
+	if err != nil {
+		return false, err
+	}
+	return cj.EnrolledBy(cronjob.GetLabels()), nil
+}
+
+// KillType returns current killtype config label for update
+func (cj *CronJob) KillType(client victims.VictimKubeClient) (string, error) {
+	cronjob, err := cj.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	return cj.KillTypeFrom(cronjob.GetLabels())
+}
+
+// KillValue returns current killvalue config label for update
+func (cj *CronJob) KillValue(client victims.VictimKubeClient) (int, error) {
+	cronjob, err := cj.get(client)
+	if err != nil {
+		return -1, err
+	}
+
+	return cj.KillValueFrom(cronjob.GetLabels())
+}
+
+// Annotate merges the annotations into the cronjob
+func (cj *CronJob) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(cj, annotations, client.Kube().BatchV1().CronJobs(cj.Namespace()).Patch)
+}
+
+// VerifyRecovery waits for the jobs of the cronjob that were running when
+// it was attacked to finish, and returns an error if any of them failed
+// or did not finish within timeout
+func (cj *CronJob) VerifyRecovery(client victims.VictimKubeClient, attackedAt time.Time, timeout time.Duration) error {
+	var attacked []batchv1.Job
+	err := wait.PollUntilContextTimeout(context.TODO(), jobs.PollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		attacked, err = cj.jobsRunningAt(client, attackedAt)
+		if err != nil {
+			return false, err
+		}
+		for _, job := range attacked {
+			if _, finished := jobs.FinishedCondition(&job); !finished {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s jobs did not finish within %s: %v", cj.Kind(), cj.Name(), timeout, err)
+	}
+
+	var failed []string
+	for _, job := range attacked {
+		if condition, _ := jobs.FinishedCondition(&job); condition.Type == batchv1.JobFailed {
+			failed = append(failed, job.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s %s jobs failed: %s", cj.Kind(), cj.Name(), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Returns the jobs of the cronjob that had started and not finished at t
+func (cj *CronJob) jobsRunningAt(client victims.VictimKubeClient, t time.Time) ([]batchv1.Job, error) {
+	owned, err := cj.jobs(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var running []batchv1.Job
+	for _, job := range owned {
+		if job.Status.StartTime == nil || job.Status.StartTime.After(t) {
+			continue
+		}
+		if condition, finished := jobs.FinishedCondition(&job); finished && condition.LastTransitionTime.Time.Before(t) {
+			continue
+		}
+		running = append(running, job)
+	}
+	return running, nil
+}
+
+// Returns the jobs controlled by the cronjob
+func (cj *CronJob) jobs(client victims.VictimKubeClient) ([]batchv1.Job, error) {
+	cronjob, err := cj.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	joblist, err := client.Kube().BatchV1().Jobs(cj.Namespace()).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []batchv1.Job
+	for _, job := range joblist.Items {
+		if controller := metav1.GetControllerOf(&job); controller != nil && controller.UID == cronjob.UID {
+			owned = append(owned, job)
+		}
+	}
+	return owned, nil
+}
+
+// Returns the pods of the active jobs of the cronjob
+func (cj *CronJob) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	owned, err := cj.jobs(client)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+	for _, job := range owned {
+		if _, finished := jobs.FinishedCondition(&job); finished {
+			continue
+		}
+		jobPods, err := victims.PodsControlledBy(client, cj.Namespace(), job.Spec.Selector, job.UID)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, jobPods...)
+	}
+	return pods, nil
+}
@@ -0,0 +1,67 @@
+package custom
+
+import (
+	"fmt"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Resource is a victim of a custom resource kind listed in config.CustomResources
+type Resource struct {
+	*victims.VictimBase
+
+	resource config.CustomResource
+}
+
+// New creates a new instance of Resource
+func New(obj *unstructured.Unstructured, resource config.CustomResource) (*Resource, error) {
+	var ident string
+	if resource.PodSelection == config.PodSelectionIdentifier {
+		var err error
+		if ident, err = identifier(obj); err != nil {
+			return nil, err
+		}
+	}
+	mtbf, err := meanTimeBetweenFailures(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	base := victims.New(obj.GetKind(), obj.GetName(), obj.GetNamespace(), ident, mtbf)
+	base.ReadHistory(obj)
+
+	r := &Resource{VictimBase: base, resource: resource}
+	switch resource.PodSelection {
+	case config.PodSelectionSelector:
+		base.SetPodFinder(r.podsBySelector)
+	case config.PodSelectionOwner:
+		base.SetPodFinder(r.podsByOwner)
+	}
+
+	return r, nil
+}
+
+// Returns the value of the label defined by config.IdentLabelKey
+// Only required when pods are selected by the identifier label
+func identifier(obj *unstructured.Unstructured) (string, error) {
+	identifier, ok := obj.GetLabels()[config.IdentLabelKey]
+	if !ok {
+		return "", fmt.Errorf("%s %s does not have %s label", obj.GetKind(), obj.GetName(), config.IdentLabelKey)
+	}
+	return identifier, nil
+}
+
+// Read the mean-time-between-failures value defined by the resource
+// in the label defined by config.MtbfLabelKey
+func meanTimeBetweenFailures(obj *unstructured.Unstructured) (time.Duration, error) {
+	mtbf, ok := obj.GetLabels()[config.MtbfLabelKey]
+	if !ok {
+		return -1, fmt.Errorf("%s %s does not have %s label", obj.GetKind(), obj.GetName(), config.MtbfLabelKey)
+	}
+
+	return victims.ParseMtbf(mtbf)
+}
@@ -0,0 +1,186 @@
+package custom
+
+import (
+	"testing"
+
+	"kube-monkey/internal/pkg/calendar"
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	IDENTIFIER = "kube-monkey-id"
+	NAME       = "widget"
+	NAMESPACE  = metav1.NamespaceDefault
+	UID        = types.UID("widget-uid")
+)
+
+var widgets = config.CustomResource{
+	Group:        "example.com",
+	Version:      "v1",
+	Resource:     "widgets",
+	PodSelection: config.PodSelectionIdentifier,
+}
+
+func newWidget(labels map[string]string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetAPIVersion("example.com/v1")
+	obj.SetKind("Widget")
+	obj.SetName(NAME)
+	obj.SetNamespace(NAMESPACE)
+	obj.SetUID(UID)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func newPod(name string, labels map[string]string, owner types.UID) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: NAMESPACE,
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if owner != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: "Widget", Name: NAME, UID: owner, Controller: &controller}}
+	}
+	return pod
+}
+
+func newVictimClient(widget *unstructured.Unstructured, pods ...runtime.Object) victims.VictimKubeClient {
+	scheme := runtime.NewScheme()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{widgets.GroupVersionResource(): "WidgetList"}, widget)
+	return victims.NewVictimClient(fake.NewSimpleClientset(pods...), dynamicClient)
+}
+
+func TestNew(t *testing.T) {
+	widget := newWidget(map[string]string{
+		config.IdentLabelKey: IDENTIFIER,
+		config.MtbfLabelKey:  "1",
+	}, nil)
+
+	r, err := New(widget, widgets)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Widget", r.Kind())
+	assert.Equal(t, NAME, r.Name())
+	assert.Equal(t, NAMESPACE, r.Namespace())
+	assert.Equal(t, IDENTIFIER, r.Identifier())
+	assert.Equal(t, calendar.Day, r.Mtbf())
+}
+
+func TestNewIdentifierOptional(t *testing.T) {
+	widget := newWidget(map[string]string{config.MtbfLabelKey: "1"}, nil)
+
+	_, err := New(widget, widgets)
+	assert.Errorf(t, err, "Expected an error if "+config.IdentLabelKey+" label doesn't exist")
+
+	owned := widgets
+	owned.PodSelection = config.PodSelectionOwner
+	_, err = New(widget, owned)
+	assert.NoError(t, err, "Expected no "+config.IdentLabelKey+" label to be required for pod selection by owner")
+}
+
+func TestEligibleResources(t *testing.T) {
+	widget := newWidget(map[string]string{
+		config.IdentLabelKey: IDENTIFIER,
+		config.MtbfLabelKey:  "1",
+	}, nil)
+	client := newVictimClient(widget)
+
+	victims, err := EligibleResources(client.Dynamic(), widgets, NAMESPACE, &metav1.ListOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, victims, 1)
+}
+
+func TestIsEnrolled(t *testing.T) {
+	widget := newWidget(map[string]string{
+		config.IdentLabelKey:   IDENTIFIER,
+		config.MtbfLabelKey:    "1",
+		config.EnabledLabelKey: config.EnabledLabelValue,
+	}, nil)
+	r, _ := New(widget, widgets)
+
+	b, err := r.IsEnrolled(newVictimClient(widget))
+
+	assert.NoError(t, err)
+	assert.True(t, b, "Expected widget to be enrolled")
+}
+
+func TestPodsBySelector(t *testing.T) {
+	widget := newWidget(map[string]string{config.MtbfLabelKey: "1"}, map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": "widget"},
+		},
+	})
+	selected := widgets
+	selected.PodSelection = config.PodSelectionSelector
+	selected.SelectorPath = "spec.selector"
+
+	r, err := New(widget, selected)
+	assert.NoError(t, err)
+
+	client := newVictimClient(widget,
+		newPod("widget-0", map[string]string{"app": "widget"}, ""),
+		newPod("other-0", map[string]string{"app": "other"}, ""),
+	)
+	pods, err := r.Pods(client)
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, "widget-0", pods[0].Name)
+}
+
+func TestPodsByOwner(t *testing.T) {
+	widget := newWidget(map[string]string{config.MtbfLabelKey: "1"}, nil)
+	owned := widgets
+	owned.PodSelection = config.PodSelectionOwner
+
+	r, err := New(widget, owned)
+	assert.NoError(t, err)
+
+	// Owned by the widget, but controlled by another resource
+	referenced := newPod("referenced-0", nil, "other-uid")
+	referenced.OwnerReferences = append(referenced.OwnerReferences, metav1.OwnerReference{Kind: "Widget", Name: NAME, UID: UID})
+
+	client := newVictimClient(widget,
+		newPod("widget-0", nil, UID),
+		newPod("other-0", nil, "other-uid"),
+		referenced,
+	)
+	pods, err := r.RunningPods(client)
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, "widget-0", pods[0].Name)
+}
+
+func TestPodSelector(t *testing.T) {
+	widget := newWidget(nil, nil)
+	widget.Object["status"] = map[string]interface{}{"selector": "app=widget"}
+
+	selector, err := podSelector(widget, "status.selector")
+	assert.NoError(t, err)
+	assert.Equal(t, "app=widget", selector.String())
+
+	_, err = podSelector(widget, "spec.selector")
+	assert.Error(t, err, "Expected an error for a missing selector")
+
+	widget.Object["status"] = map[string]interface{}{"selector": ""}
+	_, err = podSelector(widget, "status.selector")
+	assert.Error(t, err, "Expected an error for an empty selector")
+}
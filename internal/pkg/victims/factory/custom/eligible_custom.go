@@ -0,0 +1,171 @@
+package custom
+
+//All these functions require api access through the dynamic client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// IsEligible checks if the custom resource kind is served by the apiserver
+func IsEligible(dynamicClient dynamic.Interface, gvr schema.GroupVersionResource) bool {
+	_, err := dynamicClient.Resource(gvr).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	return err == nil
+}
+
+// EligibleResources gets all eligible resources of the kind that opted in (filtered by config.EnabledLabel)
+func EligibleResources(dynamicClient dynamic.Interface, resource config.CustomResource, namespace string, filter *metav1.ListOptions) (eligVictims []victims.Victim, err error) {
+	unstructuredList, err := dynamicClient.Resource(resource.GroupVersionResource()).Namespace(namespace).List(context.TODO(), *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range unstructuredList.Items {
+		itemCopy := item
+		victim, err := New(&itemCopy, resource)
+		if err != nil {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", item.GetKind(), item.GetName(), err.Error())
+			victims.ReportSkipped(item.GetKind(), item.GetNamespace(), item.GetName(), err.Error())
+			continue
+		}
+
+		eligVictims = append(eligVictims, victim)
+	}
+
+	return
+}
+
+// Returns the resource of the victim, looked up once per attack
+func (r *Resource) get(client victims.VictimKubeClient) (*unstructured.Unstructured, error) {
+	return victims.Lookup(client, r, func() (*unstructured.Unstructured, error) {
+		return client.Dynamic().Resource(r.resource.GroupVersionResource()).Namespace(r.Namespace()).Get(context.TODO(), r.Name(), metav1.GetOptions{})
+	})
+}
+
+/* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
+
+// IsEnrolled checks if the resource is currently enrolled in kube-monkey
+func (r *Resource) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return false, err
+	}
+	return r.EnrolledBy(obj.GetLabels()), nil
+}
+
+// KillType returns current killtype config label for update
+func (r *Resource) KillType(client victims.VictimKubeClient) (string, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	return r.KillTypeFrom(obj.GetLabels())
+}
+
+// KillValue returns current killvalue config label for update
+func (r *Resource) KillValue(client victims.VictimKubeClient) (int, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return -1, err
+	}
+
+	return r.KillValueFrom(obj.GetLabels())
+}
+
+// Annotate merges the annotations into the resource
+func (r *Resource) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(r, annotations, client.Dynamic().Resource(r.resource.GroupVersionResource()).Namespace(r.Namespace()).Patch)
+}
+
+/* Below methods find the pods of the resource when they are not selected by the identifier label */
+
+// Returns the pods matching the label selector at the configured path of the resource
+func (r *Resource) podsBySelector(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := podSelector(obj, r.resource.SelectorPath)
+	if err != nil {
+		return nil, err
+	}
+
+	podlist, err := client.Kube().CoreV1().Pods(r.Namespace()).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return podlist.Items, nil
+}
+
+// Returns the pods controlled by the resource, as other owners
+// of a pod neither manage it nor recreate it
+func (r *Resource) podsByOwner(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	obj, err := r.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	podlist, err := client.Kube().CoreV1().Pods(r.Namespace()).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+	for _, pod := range podlist.Items {
+		if ref := metav1.GetControllerOf(&pod); ref != nil && ref.UID == obj.GetUID() {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// Reads the label selector at the dot separated path of the object
+// The selector is either a metav1.LabelSelector or its string form,
+// as found in the status of resources with a scale subresource
+func podSelector(obj *unstructured.Unstructured, path string) (labels.Selector, error) {
+	field, found, err := unstructured.NestedFieldNoCopy(obj.Object, strings.Split(path, ".")...)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s %s does not have a selector at %s", obj.GetKind(), obj.GetName(), path)
+	}
+
+	var selector labels.Selector
+	switch value := field.(type) {
+	case string:
+		selector, err = labels.Parse(value)
+	case map[string]interface{}:
+		labelSelector := &metav1.LabelSelector{}
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(value, labelSelector); err == nil {
+			selector, err = metav1.LabelSelectorAsSelector(labelSelector)
+		}
+	default:
+		err = fmt.Errorf("unexpected type %T", field)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s %s has an invalid selector at %s: %v", obj.GetKind(), obj.GetName(), path, err)
+	}
+
+	// An empty selector would match every pod in the namespace
+	if selector.Empty() {
+		return nil, fmt.Errorf("%s %s has an empty selector at %s", obj.GetKind(), obj.GetName(), path)
+	}
+	return selector, nil
+}
@@ -2,7 +2,7 @@ package daemonsets
 
 import (
 	"fmt"
-	"strconv"
+	"time"
 
 	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
@@ -14,50 +14,41 @@ type DaemonSet struct {
 	*victims.VictimBase
 }
 
+// Kind of the daemonsets, i.e. v1.DaemonSet
+var kind = fmt.Sprintf("%T", appsv1.DaemonSet{})
+
 // New creates a new instance of DaemonSet
 func New(dep *appsv1.DaemonSet) (*DaemonSet, error) {
-	ident, err := identifier(dep)
-	if err != nil {
-		return nil, err
-	}
+	ident := identifier(dep)
 	mtbf, err := meanTimeBetweenFailures(dep)
 	if err != nil {
 		return nil, err
 	}
-	kind := fmt.Sprintf("%T", *dep)
 
-	return &DaemonSet{VictimBase: victims.New(kind, dep.Name, dep.Namespace, ident, mtbf)}, nil
+	base := victims.New(kind, dep.Name, dep.Namespace, ident, mtbf)
+	base.ReadHistory(dep)
+
+	victim := &DaemonSet{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
 }
 
 // Returns the value of the label defined by config.IdentLabelKey
-// from the DaemonSet labels
-// This label should be unique to a DaemonSet, and is used to
-// identify the pods that belong to this DaemonSet, as pods
-// inherit labels from the DaemonSet
-func identifier(kubekind *appsv1.DaemonSet) (string, error) {
-	identifier, ok := kubekind.Labels[config.IdentLabelKey]
-	if !ok {
-		return "", fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.IdentLabelKey)
-	}
-	return identifier, nil
+// from the DaemonSet labels, if any
+// The label is optional, as the pods that belong to this DaemonSet
+// are found through its selector and verified through owner references
+func identifier(kubekind *appsv1.DaemonSet) string {
+	return kubekind.Labels[config.IdentLabelKey]
 }
 
 // Read the mean-time-between-failures value defined by the DaemonSet
 // in the label defined by config.MtbfLabelKey
-func meanTimeBetweenFailures(kubekind *appsv1.DaemonSet) (int, error) {
+func meanTimeBetweenFailures(kubekind *appsv1.DaemonSet) (time.Duration, error) {
 	mtbf, ok := kubekind.Labels[config.MtbfLabelKey]
 	if !ok {
 		return -1, fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.MtbfLabelKey)
 	}
 
-	mtbfInt, err := strconv.Atoi(mtbf)
-	if err != nil {
-		return -1, err
-	}
-
-	if !(mtbfInt > 0) {
-		return -1, fmt.Errorf("Invalid value for label %s: %d", config.MtbfLabelKey, mtbfInt)
-	}
-
-	return mtbfInt, nil
+	return victims.ParseMtbf(mtbf)
 }
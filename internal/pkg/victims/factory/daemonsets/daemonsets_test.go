@@ -3,6 +3,7 @@ package daemonsets
 import (
 	"testing"
 
+	"kube-monkey/internal/pkg/calendar"
 	"kube-monkey/internal/pkg/config"
 
 	"github.com/stretchr/testify/assert"
@@ -43,19 +44,20 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, NAME, ds.Name())
 	assert.Equal(t, NAMESPACE, ds.Namespace())
 	assert.Equal(t, IDENTIFIER, ds.Identifier())
-	assert.Equal(t, 1, ds.Mtbf())
+	assert.Equal(t, calendar.Day, ds.Mtbf())
 }
 
-func TestInvalidIdentifier(t *testing.T) {
+func TestOptionalIdentifier(t *testing.T) {
 	v1ds := newDaemonSet(
 		NAME,
 		map[string]string{
 			config.MtbfLabelKey: "1",
 		},
 	)
-	_, err := New(&v1ds)
+	victim, err := New(&v1ds)
 
-	assert.Errorf(t, err, "Expected an error if "+config.IdentLabelKey+" label doesn't exist")
+	assert.NoError(t, err, "Expected "+config.IdentLabelKey+" label to be optional")
+	assert.Empty(t, victim.Identifier())
 }
 
 func TestInvalidMtbf(t *testing.T) {
@@ -5,15 +5,16 @@ package daemonsets
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/golang/glog"
 
-	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
 
 	kube "k8s.io/client-go/kubernetes"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,14 +28,8 @@ func EligibleDaemonSets(clientset kube.Interface, namespace string, filter *meta
 	for _, vic := range enabledVictims.Items {
 		victim, err := New(&vic)
 		if err != nil {
-			glog.Warningf("Skipping eligible %T %s because of error: %s", vic, vic.Name, err.Error())
-			continue
-		}
-
-		// TODO: After generating whitelisting ns list, this will move to factory.
-		// IsBlacklisted will change to something like IsAllowedNamespace
-		// and will only be used to verify at time of scheduled execution
-		if victim.IsBlacklisted() {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", kind, vic.Name, err.Error())
+			victims.ReportSkipped(kind, vic.Namespace, vic.Name, err.Error())
 			continue
 		}
 
@@ -44,52 +39,107 @@ func EligibleDaemonSets(clientset kube.Interface, namespace string, filter *meta
 	return
 }
 
+// Returns the daemonset of the victim, looked up once per attack
+func (d *DaemonSet) get(client victims.VictimKubeClient) (*appsv1.DaemonSet, error) {
+	return victims.Lookup(client, d, func() (*appsv1.DaemonSet, error) {
+		return client.Kube().AppsV1().DaemonSets(d.Namespace()).Get(context.TODO(), d.Name(), metav1.GetOptions{})
+	})
+}
+
 /* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
 
 // IsEnrolled checks if the daemonset is currently enrolled in kube-monkey
 func (d *DaemonSet) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
-	daemonset, err := client.Kube().AppsV1().DaemonSets(d.Namespace()).Get(context.TODO(), d.Name(), metav1.GetOptions{})
+	daemonset, err := d.get(client)
 	if err != nil {
 		return false, err
 	}
-	return daemonset.Labels[config.EnabledLabelKey] == config.EnabledLabelValue, nil
+	return d.EnrolledBy(daemonset.GetLabels()), nil
 }
 
 // KillType returns current killtype config label for update
 func (d *DaemonSet) KillType(client victims.VictimKubeClient) (string, error) {
-	daemonset, err := client.Kube().AppsV1().DaemonSets(d.Namespace()).Get(context.TODO(), d.Name(), metav1.GetOptions{})
+	daemonset, err := d.get(client)
 	if err != nil {
 		return "", err
 	}
 
-	killType, ok := daemonset.Labels[config.KillTypeLabelKey]
-	if !ok {
-		return "", fmt.Errorf("%s %s does not have %s label", d.Kind(), d.Name(), config.KillTypeLabelKey)
-	}
-
-	return killType, nil
+	return d.KillTypeFrom(daemonset.GetLabels())
 }
 
 // KillValue returns current killvalue config label for update
 func (d *DaemonSet) KillValue(client victims.VictimKubeClient) (int, error) {
-	daemonset, err := client.Kube().AppsV1().DaemonSets(d.Namespace()).Get(context.TODO(), d.Name(), metav1.GetOptions{})
+	daemonset, err := d.get(client)
 	if err != nil {
 		return -1, err
 	}
 
-	killMode, ok := daemonset.Labels[config.KillValueLabelKey]
-	if !ok {
-		return -1, fmt.Errorf("%s %s does not have %s label", d.Kind(), d.Name(), config.KillValueLabelKey)
+	return d.KillValueFrom(daemonset.GetLabels())
+}
+
+// Annotate merges the annotations into the daemonset
+func (d *DaemonSet) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(d, annotations, client.Kube().AppsV1().DaemonSets(d.Namespace()).Patch)
+}
+
+// Returns the pods of the daemonset on the nodes selected by NodeSelectorAnnotationKey,
+// found through its selector and verified through their owner references
+func (d *DaemonSet) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	daemonset, err := d.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := victims.PodsControlledBy(client, d.Namespace(), daemonset.Spec.Selector, daemonset.UID)
+	if err != nil {
+		return nil, err
 	}
 
-	killModeInt, err := strconv.Atoi(killMode)
+	return podsOnSelectedNodes(client, pods, daemonset.Annotations[NodeSelectorAnnotationKey])
+}
+
+// UnhealthyReason returns why the daemonset is rolling out or degraded, if it is
+func (d *DaemonSet) UnhealthyReason(client victims.VictimKubeClient) (string, error) {
+	daemonset, err := d.get(client)
 	if err != nil {
-		return -1, fmt.Errorf("%s %s has an invalid killMode: %v", d.Kind(), d.Name(), killMode)
+		return "", err
+	}
+
+	if !victims.SkipsUnhealthy(daemonset.Labels) {
+		return "", nil
+	}
+
+	if reason := victims.GenerationLagReason(daemonset.Generation, daemonset.Status.ObservedGeneration); reason != "" {
+		return reason, nil
 	}
 
-	if !(killModeInt > 0) {
-		return -1, fmt.Errorf("Invalid value for label %s: %d", config.KillValueLabelKey, killModeInt)
+	// Pods of an OnDelete daemonset are only updated when deleted
+	rollingUpdate := daemonset.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType
+
+	status := daemonset.Status
+	switch {
+	case rollingUpdate && status.UpdatedNumberScheduled < status.DesiredNumberScheduled:
+		return fmt.Sprintf("rollout in progress: %d of %d pods updated", status.UpdatedNumberScheduled, status.DesiredNumberScheduled), nil
+	case status.NumberUnavailable > 0:
+		return fmt.Sprintf("degraded: %d of %d pods unavailable", status.NumberUnavailable, status.DesiredNumberScheduled), nil
+	}
+
+	return "", nil
+}
+
+// VerifyRecovery waits for the desired replicas of the daemonset to be ready again
+func (d *DaemonSet) VerifyRecovery(client victims.VictimKubeClient, attackedAt time.Time, timeout time.Duration) error {
+	return victims.WaitForReadyReplicas(client, d, timeout, d.replicas)
+}
+
+// Returns the desired replicas of the daemonset and its pods
+func (d *DaemonSet) replicas(client victims.VictimKubeClient) (int32, []corev1.Pod, error) {
+	daemonset, err := d.get(client)
+	if err != nil {
+		return 0, nil, err
 	}
 
-	return killModeInt, nil
+	// The pods on all nodes, not only on the nodes selected for attacks
+	pods, err := victims.PodsControlledBy(client, d.Namespace(), daemonset.Spec.Selector, daemonset.UID)
+	return daemonset.Status.DesiredNumberScheduled, pods, err
 }
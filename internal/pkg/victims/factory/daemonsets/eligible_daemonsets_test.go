@@ -7,6 +7,7 @@ import (
 	"kube-monkey/internal/pkg/victims"
 
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 )
@@ -156,3 +157,36 @@ func TestKillValue(t *testing.T) {
 
 	assert.Equalf(t, kill, 1, "Unexpected a kill value, got %d", kill)
 }
+
+func TestUnhealthyReason(t *testing.T) {
+	newStatusDaemonSet := func(labels map[string]string, strategy appsv1.DaemonSetUpdateStrategyType, status appsv1.DaemonSetStatus) *appsv1.DaemonSet {
+		v1ds := newDaemonSet(NAME, labels)
+		v1ds.Generation = 2
+		v1ds.Spec.UpdateStrategy.Type = strategy
+		v1ds.Status = status
+		return &v1ds
+	}
+
+	labels := map[string]string{config.MtbfLabelKey: "1"}
+	cases := []struct {
+		strategy appsv1.DaemonSetUpdateStrategyType
+		status   appsv1.DaemonSetStatus
+		expected string
+	}{
+		{appsv1.RollingUpdateDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3}, ""},
+		{appsv1.RollingUpdateDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3}, "rollout in progress: generation 2 not observed yet, observed 1"},
+		{appsv1.RollingUpdateDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 1}, "rollout in progress: 1 of 3 pods updated"},
+		{appsv1.OnDeleteDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 1}, ""},
+		{appsv1.RollingUpdateDaemonSetStrategyType, appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberUnavailable: 1}, "degraded: 1 of 3 pods unavailable"},
+	}
+
+	for _, c := range cases {
+		v1ds := newStatusDaemonSet(labels, c.strategy, c.status)
+		ds, _ := New(v1ds)
+
+		reason, err := ds.UnhealthyReason(victims.NewVictimClient(fake.NewSimpleClientset(v1ds), nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, reason)
+	}
+}
@@ -0,0 +1,136 @@
+package daemonsets
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/victims"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// NodeSelectorAnnotationKey restricts the candidate pods of a daemonset
+	// to the pods on the nodes matching this label selector,
+	// e.g. "node-role.kubernetes.io/worker,topology.kubernetes.io/zone=eu-west-1a"
+	// An annotation is used as label values cannot hold a selector
+	NodeSelectorAnnotationKey = "kube-monkey/node-selector"
+
+	// KillRandomNodesLabelValue is a kill mode terminating the pods of
+	// the daemonset on kill-value random nodes simultaneously
+	KillRandomNodesLabelValue = "random-nodes"
+)
+
+// Filters the pods on the nodes matching the node selector.
+// An empty node selector selects all nodes
+func podsOnSelectedNodes(client victims.VictimKubeClient, pods []corev1.Pod, nodeSelector string) ([]corev1.Pod, error) {
+	if nodeSelector == "" {
+		return pods, nil
+	}
+
+	selector, err := labels.Parse(nodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid value for annotation %s: %s: %v", NodeSelectorAnnotationKey, nodeSelector, err)
+	}
+
+	nodes, err := client.Kube().CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	nodeNames := sets.New[string]()
+	for _, node := range nodes.Items {
+		nodeNames.Insert(node.Name)
+	}
+
+	var selected []corev1.Pod
+	for _, pod := range pods {
+		if nodeNames.Has(pod.Spec.NodeName) {
+			selected = append(selected, pod)
+		}
+	}
+	return selected, nil
+}
+
+// HandlesKillMode checks if the kill mode is specific to daemonsets
+func (d *DaemonSet) HandlesKillMode(killType string) bool {
+	return killType == KillRandomNodesLabelValue
+}
+
+// Kill executes a kill mode specific to daemonsets. The number of nodes
+// is killValue if positive, and the kill-value label otherwise
+func (d *DaemonSet) Kill(client victims.VictimKubeClient, killType string, killValue int) (int, error) {
+	switch killType {
+	case KillRandomNodesLabelValue:
+		if killValue <= 0 {
+			var err error
+			if killValue, err = d.KillValue(client); err != nil {
+				return 0, err
+			}
+		}
+		return d.deletePodsOnRandomNodes(client, killValue)
+	default:
+		return 0, fmt.Errorf("failed to recognize KillType label for %s %s", d.Kind(), d.Name())
+	}
+}
+
+// Terminates the running pods of the daemonset on numNodes random nodes
+// simultaneously, simulating the loss of the agent on several nodes at once
+// Returns the number of pods terminated
+func (d *DaemonSet) deletePodsOnRandomNodes(client victims.VictimKubeClient, numNodes int) (int, error) {
+	pods, err := d.RunningPods(client)
+	if err != nil {
+		return 0, err
+	}
+
+	podsByNode := map[string][]string{}
+	for _, pod := range pods {
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod.Name)
+	}
+
+	nodes := sets.List(sets.KeySet(podsByNode))
+	switch {
+	case len(nodes) == 0:
+		return 0, victims.NoPodsError(d.Kind(), d.Name())
+	case len(nodes) < numNodes:
+		glog.Warningf("%s %s has running pods on only %d nodes, but %d nodes requested", d.Kind(), d.Name(), len(nodes), numNodes)
+		numNodes = len(nodes)
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+
+	var targets []string
+	for _, node := range nodes[:numNodes] {
+		glog.V(6).Infof("Terminating pods on node %s for %s %s/%s", node, d.Kind(), d.Namespace(), d.Name())
+		targets = append(targets, podsByNode[node]...)
+	}
+
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			errs[i] = d.DeletePod(client, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	deleted := 0
+	for _, err := range errs {
+		if err == nil {
+			deleted++
+		}
+	}
+	return deleted, utilerrors.NewAggregate(errs)
+}
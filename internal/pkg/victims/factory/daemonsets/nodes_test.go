@@ -0,0 +1,162 @@
+package daemonsets
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var selector = map[string]string{"app": "agent"}
+
+func newSelectingDaemonSet(labels, annotations map[string]string) *appsv1.DaemonSet {
+	v1ds := newDaemonSet(NAME, labels)
+	v1ds.UID = "daemonset-uid"
+	v1ds.Annotations = annotations
+	v1ds.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+	return &v1ds
+}
+
+// Creates a node with the given labels and the daemonset pod running on it
+func newNodeWithPod(v1ds *appsv1.DaemonSet, name string, labels map[string]string) []runtime.Object {
+	controller := true
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%s", NAME, name),
+			Namespace:       NAMESPACE,
+			Labels:          selector,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: NAME, UID: v1ds.UID, Controller: &controller}},
+		},
+		Spec: corev1.PodSpec{NodeName: name},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	return []runtime.Object{node, pod}
+}
+
+func newNodesClient(v1ds *appsv1.DaemonSet) *fake.Clientset {
+	objects := []runtime.Object{v1ds}
+	objects = append(objects, newNodeWithPod(v1ds, "node-a", map[string]string{"zone": "a"})...)
+	objects = append(objects, newNodeWithPod(v1ds, "node-b", map[string]string{"zone": "b"})...)
+	objects = append(objects, newNodeWithPod(v1ds, "node-c", map[string]string{"zone": "b"})...)
+	return fake.NewSimpleClientset(objects...)
+}
+
+func TestPodsOnSelectedNodes(t *testing.T) {
+	v1ds := newSelectingDaemonSet(map[string]string{config.MtbfLabelKey: "1"}, nil)
+	ds, _ := New(v1ds)
+
+	pods, err := ds.Pods(victims.NewVictimClient(newNodesClient(v1ds), nil))
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 3, "Expected all pods without a node selector")
+
+	v1ds = newSelectingDaemonSet(map[string]string{config.MtbfLabelKey: "1"}, map[string]string{NodeSelectorAnnotationKey: "zone=b"})
+
+	pods, err = ds.Pods(victims.NewVictimClient(newNodesClient(v1ds), nil))
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 2)
+	for _, pod := range pods {
+		assert.NotEqual(t, "node-a", pod.Spec.NodeName)
+	}
+
+	v1ds = newSelectingDaemonSet(map[string]string{config.MtbfLabelKey: "1"}, map[string]string{NodeSelectorAnnotationKey: "zone in b"})
+
+	_, err = ds.Pods(victims.NewVictimClient(newNodesClient(v1ds), nil))
+
+	assert.Error(t, err, "Expected an error for an invalid node selector")
+}
+
+func TestKillRandomNodes(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DryRun, false)
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	v1ds := newSelectingDaemonSet(map[string]string{
+		config.MtbfLabelKey:      "1",
+		config.KillTypeLabelKey:  KillRandomNodesLabelValue,
+		config.KillValueLabelKey: "2",
+	}, nil)
+	ds, _ := New(v1ds)
+	client := newNodesClient(v1ds)
+
+	assert.True(t, ds.HandlesKillMode(KillRandomNodesLabelValue))
+	assert.False(t, ds.HandlesKillMode(config.KillFixedLabelValue))
+
+	killed, err := ds.Kill(victims.NewVictimClient(client, nil), KillRandomNodesLabelValue, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, killed)
+
+	pods, _ := client.CoreV1().Pods(NAMESPACE).List(context.TODO(), metav1.ListOptions{})
+	assert.Len(t, pods.Items, 1, "Expected the pods on 2 of the 3 nodes to be terminated")
+}
+
+func TestKillRandomNodesOverriddenValue(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DryRun, false)
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	v1ds := newSelectingDaemonSet(map[string]string{
+		config.MtbfLabelKey:      "1",
+		config.KillTypeLabelKey:  KillRandomNodesLabelValue,
+		config.KillValueLabelKey: "2",
+	}, nil)
+	ds, _ := New(v1ds)
+	client := newNodesClient(v1ds)
+
+	killed, err := ds.Kill(victims.NewVictimClient(client, nil), KillRandomNodesLabelValue, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, killed, "Expected the kill value to override the label")
+
+	pods, _ := client.CoreV1().Pods(NAMESPACE).List(context.TODO(), metav1.ListOptions{})
+	assert.Len(t, pods.Items, 2)
+}
+
+func TestKillRandomNodesMoreThanAvailable(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DryRun, false)
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	v1ds := newSelectingDaemonSet(map[string]string{
+		config.MtbfLabelKey:      "1",
+		config.KillTypeLabelKey:  KillRandomNodesLabelValue,
+		config.KillValueLabelKey: "5",
+	}, map[string]string{NodeSelectorAnnotationKey: "zone=b"})
+	ds, _ := New(v1ds)
+	client := newNodesClient(v1ds)
+
+	killed, err := ds.Kill(victims.NewVictimClient(client, nil), KillRandomNodesLabelValue, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, killed)
+
+	pods, _ := client.CoreV1().Pods(NAMESPACE).List(context.TODO(), metav1.ListOptions{})
+	assert.Len(t, pods.Items, 1)
+	assert.Equal(t, "node-a", pods.Items[0].Spec.NodeName, "Expected only the pods on selected nodes to be terminated")
+}
@@ -2,7 +2,7 @@ package deployments
 
 import (
 	"fmt"
-	"strconv"
+	"time"
 
 	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
@@ -14,50 +14,41 @@ type Deployment struct {
 	*victims.VictimBase
 }
 
+// Kind of the deployments, i.e. v1.Deployment
+var kind = fmt.Sprintf("%T", appsv1.Deployment{})
+
 // New creates a new instance of Deployment
 func New(dep *appsv1.Deployment) (*Deployment, error) {
-	ident, err := identifier(dep)
-	if err != nil {
-		return nil, err
-	}
+	ident := identifier(dep)
 	mtbf, err := meanTimeBetweenFailures(dep)
 	if err != nil {
 		return nil, err
 	}
-	kind := fmt.Sprintf("%T", *dep)
 
-	return &Deployment{VictimBase: victims.New(kind, dep.Name, dep.Namespace, ident, mtbf)}, nil
+	base := victims.New(kind, dep.Name, dep.Namespace, ident, mtbf)
+	base.ReadHistory(dep)
+
+	victim := &Deployment{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
 }
 
 // Returns the value of the label defined by config.IdentLabelKey
-// from the deployment labels
-// This label should be unique to a deployment, and is used to
-// identify the pods that belong to this deployment, as pods
-// inherit labels from the Deployment
-func identifier(kubekind *appsv1.Deployment) (string, error) {
-	identifier, ok := kubekind.Labels[config.IdentLabelKey]
-	if !ok {
-		return "", fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.IdentLabelKey)
-	}
-	return identifier, nil
+// from the deployment labels, if any
+// The label is optional, as the pods that belong to this deployment
+// are found through its selector and verified through owner references
+func identifier(kubekind *appsv1.Deployment) string {
+	return kubekind.Labels[config.IdentLabelKey]
 }
 
 // Read the mean-time-between-failures value defined by the Deployment
 // in the label defined by config.MtbfLabelKey
-func meanTimeBetweenFailures(kubekind *appsv1.Deployment) (int, error) {
+func meanTimeBetweenFailures(kubekind *appsv1.Deployment) (time.Duration, error) {
 	mtbf, ok := kubekind.Labels[config.MtbfLabelKey]
 	if !ok {
 		return -1, fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.MtbfLabelKey)
 	}
 
-	mtbfInt, err := strconv.Atoi(mtbf)
-	if err != nil {
-		return -1, err
-	}
-
-	if !(mtbfInt > 0) {
-		return -1, fmt.Errorf("Invalid value for label %s: %d", config.MtbfLabelKey, mtbfInt)
-	}
-
-	return mtbfInt, nil
+	return victims.ParseMtbf(mtbf)
 }
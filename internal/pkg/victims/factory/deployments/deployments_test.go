@@ -2,7 +2,9 @@ package deployments
 
 import (
 	"testing"
+	"time"
 
+	"kube-monkey/internal/pkg/calendar"
 	"kube-monkey/internal/pkg/config"
 
 	"github.com/stretchr/testify/assert"
@@ -43,19 +45,34 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, NAME, depl.Name())
 	assert.Equal(t, NAMESPACE, depl.Namespace())
 	assert.Equal(t, IDENTIFIER, depl.Identifier())
-	assert.Equal(t, 1, depl.Mtbf())
+	assert.Equal(t, calendar.Day, depl.Mtbf())
 }
 
-func TestInvalidIdentifier(t *testing.T) {
+func TestNewDurationMtbf(t *testing.T) {
+	v1depl := newDeployment(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "4h",
+		},
+	)
+	depl, err := New(&v1depl)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4*time.Hour, depl.Mtbf())
+}
+
+func TestOptionalIdentifier(t *testing.T) {
 	v1depl := newDeployment(
 		NAME,
 		map[string]string{
 			config.MtbfLabelKey: "1",
 		},
 	)
-	_, err := New(&v1depl)
+	victim, err := New(&v1depl)
 
-	assert.Errorf(t, err, "Expected an error if "+config.IdentLabelKey+" label doesn't exist")
+	assert.NoError(t, err, "Expected "+config.IdentLabelKey+" label to be optional")
+	assert.Empty(t, victim.Identifier())
 }
 
 func TestInvalidMtbf(t *testing.T) {
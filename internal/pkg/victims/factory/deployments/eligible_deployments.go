@@ -5,16 +5,18 @@ package deployments
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/golang/glog"
 
-	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
 
 	kube "k8s.io/client-go/kubernetes"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // EligibleDeployments gets all eligible deployments that opted in (filtered by config.EnabledLabel)
@@ -27,14 +29,8 @@ func EligibleDeployments(clientset kube.Interface, namespace string, filter *met
 	for _, vic := range enabledVictims.Items {
 		victim, err := New(&vic)
 		if err != nil {
-			glog.Warningf("Skipping eligible %T %s because of error: %s", vic, vic.Name, err.Error())
-			continue
-		}
-
-		// TODO: After generating whitelisting ns list, this will move to factory.
-		// IsBlacklisted will change to something like IsAllowedNamespace
-		// and will only be used to verify at time of scheduled execution
-		if victim.IsBlacklisted() {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", kind, vic.Name, err.Error())
+			victims.ReportSkipped(kind, vic.Namespace, vic.Name, err.Error())
 			continue
 		}
 
@@ -44,48 +40,124 @@ func EligibleDeployments(clientset kube.Interface, namespace string, filter *met
 	return
 }
 
+// Returns the deployment of the victim, looked up once per attack
+func (d *Deployment) get(client victims.VictimKubeClient) (*appsv1.Deployment, error) {
+	return victims.Lookup(client, d, func() (*appsv1.Deployment, error) {
+		return client.Kube().AppsV1().Deployments(d.Namespace()).Get(context.TODO(), d.Name(), metav1.GetOptions{})
+	})
+}
+
 /* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
 
 // IsEnrolled checks if the deployment is currently enrolled in kube-monkey
 func (d *Deployment) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
-	deployment, err := client.Kube().AppsV1().Deployments(d.Namespace()).Get(context.TODO(), d.Name(), metav1.GetOptions{})
+	deployment, err := d.get(client)
 	if err != nil {
 		return false, err
 	}
-	return deployment.Labels[config.EnabledLabelKey] == config.EnabledLabelValue, nil
+	return d.EnrolledBy(deployment.GetLabels()), nil
 }
 
 // KillType returns current killtype config label for update
 func (d *Deployment) KillType(client victims.VictimKubeClient) (string, error) {
-	deployment, err := client.Kube().AppsV1().Deployments(d.Namespace()).Get(context.TODO(), d.Name(), metav1.GetOptions{})
+	deployment, err := d.get(client)
 	if err != nil {
 		return "", err
 	}
 
-	killType, ok := deployment.Labels[config.KillTypeLabelKey]
-	if !ok {
-		return "", fmt.Errorf("%s %s does not have %s label", d.Kind(), d.Name(), config.KillTypeLabelKey)
-	}
-
-	return killType, nil
+	return d.KillTypeFrom(deployment.GetLabels())
 }
 
 // KillValue returns current killvalue config label for update
 func (d *Deployment) KillValue(client victims.VictimKubeClient) (int, error) {
-	deployment, err := client.Kube().AppsV1().Deployments(d.Namespace()).Get(context.TODO(), d.Name(), metav1.GetOptions{})
+	deployment, err := d.get(client)
 	if err != nil {
 		return -1, err
 	}
 
-	killMode, ok := deployment.Labels[config.KillValueLabelKey]
-	if !ok {
-		return -1, fmt.Errorf("%s %s does not have %s label", d.Kind(), d.Name(), config.KillValueLabelKey)
+	return d.KillValueFrom(deployment.GetLabels())
+}
+
+// Annotate merges the annotations into the deployment
+func (d *Deployment) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(d, annotations, client.Kube().AppsV1().Deployments(d.Namespace()).Patch)
+}
+
+// Returns the pods of the deployment, found through its selector and verified
+// through the owner references Deployment -> ReplicaSet -> Pod
+func (d *Deployment) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	deployment, err := d.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	listOpts, err := victims.ListOptionsForSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	replicasets, err := client.Kube().AppsV1().ReplicaSets(d.Namespace()).List(context.TODO(), *listOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var owners []types.UID
+	for _, rs := range replicasets.Items {
+		if controller := metav1.GetControllerOf(&rs); controller != nil && controller.UID == deployment.UID {
+			owners = append(owners, rs.UID)
+		}
+	}
+
+	return victims.PodsControlledBy(client, d.Namespace(), deployment.Spec.Selector, owners...)
+}
+
+// UnhealthyReason returns why the deployment is rolling out or degraded, if it is
+func (d *Deployment) UnhealthyReason(client victims.VictimKubeClient) (string, error) {
+	deployment, err := d.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	if !victims.SkipsUnhealthy(deployment.Labels) {
+		return "", nil
+	}
+
+	if reason := victims.GenerationLagReason(deployment.Generation, deployment.Status.ObservedGeneration); reason != "" {
+		return reason, nil
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
 	}
 
-	killModeInt, err := strconv.Atoi(killMode)
-	if err != nil || !(killModeInt > 0) {
-		return -1, fmt.Errorf("Invalid value for label %s: %d", config.KillValueLabelKey, killModeInt)
+	status := deployment.Status
+	switch {
+	case status.UpdatedReplicas < replicas || status.Replicas > status.UpdatedReplicas:
+		return fmt.Sprintf("rollout in progress: %d of %d replicas updated", status.UpdatedReplicas, replicas), nil
+	case status.UnavailableReplicas > 0:
+		return fmt.Sprintf("degraded: %d of %d replicas unavailable", status.UnavailableReplicas, replicas), nil
 	}
 
-	return killModeInt, nil
+	return "", nil
+}
+
+// VerifyRecovery waits for the desired replicas of the deployment to be ready again
+func (d *Deployment) VerifyRecovery(client victims.VictimKubeClient, attackedAt time.Time, timeout time.Duration) error {
+	return victims.WaitForReadyReplicas(client, d, timeout, d.replicas)
+}
+
+// Returns the desired replicas of the deployment and its pods
+func (d *Deployment) replicas(client victims.VictimKubeClient) (int32, []corev1.Pod, error) {
+	deployment, err := d.get(client)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	pods, err := d.pods(client)
+	return desired, pods, err
 }
@@ -1,13 +1,18 @@
 package deployments
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
 
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -26,6 +31,23 @@ func TestEligibleDeployments(t *testing.T) {
 	assert.Len(t, victims, 1)
 }
 
+func TestEligibleDeploymentsReportsSkipped(t *testing.T) {
+	var skipped []victims.SkippedVictim
+	unregister := victims.OnSkip(func(victim victims.SkippedVictim) { skipped = append(skipped, victim) })
+	defer unregister()
+
+	valid := newDeployment(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	invalid := newDeployment("invalid", map[string]string{config.MtbfLabelKey: "x"})
+	client := fake.NewSimpleClientset(&valid, &invalid)
+
+	eligible, _ := EligibleDeployments(client, NAMESPACE, &metav1.ListOptions{})
+
+	if assert.Len(t, eligible, 1) && assert.Len(t, skipped, 1) {
+		assert.Equal(t, eligible[0].Kind(), skipped[0].Kind, "Expected the skipped deployment to be reported with the kind of the victims")
+		assert.Equal(t, "invalid", skipped[0].Name)
+	}
+}
+
 func TestIsEnrolled(t *testing.T) {
 	v1depl := newDeployment(
 		NAME,
@@ -156,3 +178,158 @@ func TestKillValue(t *testing.T) {
 
 	assert.Equalf(t, kill, 1, "Unexpected a kill value, got %d", kill)
 }
+
+func TestAnnotate(t *testing.T) {
+	v1depl := newDeployment(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: "1",
+			config.MtbfLabelKey:  "1",
+		},
+	)
+
+	depl, _ := New(&v1depl)
+
+	client := fake.NewSimpleClientset(&v1depl)
+
+	err := depl.Annotate(victims.NewVictimClient(client, nil), map[string]string{config.LastKilledAnnotationKey: "2024-01-01T10:00:00Z"})
+	assert.NoError(t, err)
+
+	deployment, _ := client.AppsV1().Deployments(NAMESPACE).Get(context.TODO(), NAME, metav1.GetOptions{})
+	assert.Equal(t, "2024-01-01T10:00:00Z", deployment.Annotations[config.LastKilledAnnotationKey])
+}
+
+func newOwnerReference(kind, name string, uid types.UID) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, UID: uid, Controller: &controller}}
+}
+
+func TestPods(t *testing.T) {
+	selector := map[string]string{"app": "web"}
+
+	v1depl := newDeployment(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	v1depl.UID = "deployment-uid"
+	v1depl.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+
+	rs := appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            NAME + "-abc",
+			Namespace:       NAMESPACE,
+			Labels:          selector,
+			UID:             "replicaset-uid",
+			OwnerReferences: newOwnerReference("Deployment", NAME, v1depl.UID),
+		},
+	}
+	owned := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            NAME + "-abc-1",
+			Namespace:       NAMESPACE,
+			Labels:          selector,
+			OwnerReferences: newOwnerReference("ReplicaSet", rs.Name, rs.UID),
+		},
+	}
+	// Matches the selector but belongs to another workload
+	foreign := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "other-1",
+			Namespace:       NAMESPACE,
+			Labels:          selector,
+			OwnerReferences: newOwnerReference("ReplicaSet", "other", "other-uid"),
+		},
+	}
+
+	depl, _ := New(&v1depl)
+
+	client := fake.NewSimpleClientset(&v1depl, &rs, &owned, &foreign)
+
+	pods, err := depl.Pods(victims.NewVictimClient(client, nil))
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, owned.Name, pods[0].Name)
+}
+
+func TestUnhealthyReason(t *testing.T) {
+	replicas := int32(3)
+	newStatusDeployment := func(labels map[string]string, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		v1depl := newDeployment(NAME, labels)
+		v1depl.Generation = 2
+		v1depl.Spec.Replicas = &replicas
+		v1depl.Status = status
+		return &v1depl
+	}
+
+	labels := map[string]string{config.MtbfLabelKey: "1"}
+	cases := map[string]appsv1.DeploymentStatus{
+		"": {ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3},
+		"rollout in progress: generation 2 not observed yet, observed 1": {ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3},
+		"rollout in progress: 1 of 3 replicas updated":                   {ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1},
+		"degraded: 1 of 3 replicas unavailable":                          {ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, UnavailableReplicas: 1},
+	}
+
+	for expected, status := range cases {
+		v1depl := newStatusDeployment(labels, status)
+		depl, _ := New(v1depl)
+
+		reason, err := depl.UnhealthyReason(victims.NewVictimClient(fake.NewSimpleClientset(v1depl), nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, reason)
+	}
+
+	labels[config.SkipUnhealthyLabelKey] = "false"
+	v1depl := newStatusDeployment(labels, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, UnavailableReplicas: 1})
+	depl, _ := New(v1depl)
+
+	reason, err := depl.UnhealthyReason(victims.NewVictimClient(fake.NewSimpleClientset(v1depl), nil))
+
+	assert.NoError(t, err)
+	assert.Empty(t, reason, "Expected no reason if the deployment does not skip when unhealthy")
+}
+
+func TestVerifyRecovery(t *testing.T) {
+	pollInterval := victims.PollInterval
+	defer func() { victims.PollInterval = pollInterval }()
+	victims.PollInterval = time.Millisecond
+
+	selector := map[string]string{"app": "web"}
+	replicas := int32(2)
+
+	v1depl := newDeployment(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	v1depl.UID = "deployment-uid"
+	v1depl.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+	v1depl.Spec.Replicas = &replicas
+	rs := appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            NAME + "-abc",
+			Namespace:       NAMESPACE,
+			Labels:          selector,
+			UID:             "replicaset-uid",
+			OwnerReferences: newOwnerReference("Deployment", NAME, v1depl.UID),
+		},
+	}
+	newReplica := func(name string, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       NAMESPACE,
+				Labels:          selector,
+				OwnerReferences: newOwnerReference("ReplicaSet", rs.Name, rs.UID),
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+
+	depl, _ := New(&v1depl)
+	client := fake.NewSimpleClientset(&v1depl, &rs, newReplica(NAME+"-abc-1", corev1.ConditionTrue), newReplica(NAME+"-abc-2", corev1.ConditionFalse))
+
+	err := depl.VerifyRecovery(victims.NewVictimClient(client, nil), time.Now(), 10*time.Millisecond)
+	assert.ErrorContains(t, err, "(1 of 2 replicas ready)", "Expected a replica that is not ready yet not to be recovered")
+
+	_, err = client.CoreV1().Pods(NAMESPACE).Update(context.TODO(), newReplica(NAME+"-abc-2", corev1.ConditionTrue), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, depl.VerifyRecovery(victims.NewVictimClient(client, nil), time.Now(), time.Second))
+}
@@ -1,37 +1,56 @@
 /*
 Package factory is responsible for generating eligible victim kinds
 
-New types of kinds can be added easily
+New types of kinds can be added easily by registering a Provider
 */
 package factory
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/golang/glog"
 
 	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/kubernetes"
 	"kube-monkey/internal/pkg/victims"
-	"kube-monkey/internal/pkg/victims/factory/cnpg.io/postgresql/clusters"
-	"kube-monkey/internal/pkg/victims/factory/daemonsets"
-	"kube-monkey/internal/pkg/victims/factory/deployments"
-	"kube-monkey/internal/pkg/victims/factory/statefulsets"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// KindError is returned when a victim kind fails to list
+// its eligible victims in a namespace
+type KindError struct {
+	Kind      string
+	Namespace string
+	Err       error
+}
+
+func (e *KindError) Error() string {
+	return fmt.Sprintf("failed to fetch eligible %s for namespace %s: %v", e.Kind, e.Namespace, e.Err)
+}
+
+func (e *KindError) Unwrap() error {
+	return e.Err
+}
+
 // EligibleVictims gathers list of enabled/enrolled kinds for judgement by
 // the scheduler
-// This checks against config.WhitelistedNamespaces but
-// each victim checks themselves against the ns blacklist
-// TODO: fetch all namespaces from k8 apiserver to check blacklist here
-func EligibleVictims() (eligibleVictims []victims.Victim, err error) {
-	clientset, dynamicClient, err := kubernetes.CreateClient()
+// This checks against config.WhitelistedNamespaces, and against
+// config.BlacklistedNamespaces when namespaces are matched by pattern
+// or label selector. The victims are then tagged with the name of their
+// cluster, which is empty unless config.Clusters is set, and checked
+// against the ns blacklist of their cluster
+func EligibleVictims(cluster string, clients kubernetes.ClientProvider) (eligibleVictims []victims.Victim, err error) {
+	clientset, dynamicClient, err := clients.Clients()
 	if err != nil {
 		return nil, err
 	}
+	client := victims.NewVictimClient(clientset, dynamicClient)
 
 	// Verify opt-in at scheduling time
 	filter, err := enrollmentFilter()
@@ -39,43 +58,130 @@ func EligibleVictims() (eligibleVictims []victims.Victim, err error) {
 		return nil, err
 	}
 
-	for _, namespace := range config.WhitelistedNamespaces().UnsortedList() {
-		// Fetch deployments
-		deployments, err := deployments.EligibleDeployments(clientset, namespace, filter)
-		if err != nil {
-			//allow pass through to schedule other kinds and namespaces
-			glog.Warningf("Failed to fetch eligible deployments for namespace %s due to error: %s", namespace, err.Error())
+	namespaces, err := targetNamespaces(cluster, clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := EnabledProviders(client, Providers())
+	found, kindErrs := eligibleVictimsOf(client, providers, namespaces, filter)
+	for _, kindErr := range kindErrs {
+		//allow pass through to schedule other kinds and namespaces
+		glog.Warningf("Skipping kind: %s", kindErr.Error())
+		victims.ReportSkipped(kindErr.Kind, kindErr.Namespace, "", kindErr.Err.Error())
+	}
+
+	for _, victim := range found {
+		victim.SetCluster(cluster)
+		if victim.IsBlacklisted() {
+			victims.ReportSkipped(victim.Kind(), victim.Namespace(), victim.Name(), "namespace is blacklisted")
 			continue
 		}
-		eligibleVictims = append(eligibleVictims, deployments...)
+		eligibleVictims = append(eligibleVictims, victim)
+	}
 
-		// Fetch statefulsets
-		statefulsets, err := statefulsets.EligibleStatefulSets(clientset, namespace, filter)
-		if err != nil {
-			//allow pass through to schedule other kinds and namespaces
-			glog.Warningf("Failed to fetch eligible statefulsets for namespace %s due to error: %s", namespace, err.Error())
+	return eligibleVictims, nil
+}
+
+// FindVictim returns the enrolled victim of the kind, named after
+// Provider.Name, with the name in the namespace, e.g. the target of an
+// experiment. The victim is tagged with the name of its cluster, and the
+// labels of its namespace are cached for the whitelist and blacklist
+func FindVictim(cluster string, clients kubernetes.ClientProvider, kind, namespace, name string) (victims.Victim, error) {
+	clientset, dynamicClient, err := clients.Clients()
+	if err != nil {
+		return nil, err
+	}
+	client := victims.NewVictimClient(clientset, dynamicClient)
+
+	if err := recordNamespaceLabels(cluster, clientset, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %v", namespace, err)
+	}
+
+	filter, err := enrollmentFilter()
+	if err != nil {
+		return nil, err
+	}
+	filter.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+
+	for _, provider := range Providers() {
+		if provider.Name != kind {
 			continue
 		}
-		eligibleVictims = append(eligibleVictims, statefulsets...)
+		if len(EnabledProviders(client, []Provider{provider})) == 0 {
+			break
+		}
 
-		// Fetch daemonsets
-		daemonsets, err := daemonsets.EligibleDaemonSets(clientset, namespace, filter)
+		found, err := provider.List(client, namespace, filter)
 		if err != nil {
-			//allow pass through to schedule other kinds and namespaces
-			glog.Warningf("Failed to fetch eligible daemonsets for namespace %s due to error: %s", namespace, err.Error())
-			continue
+			return nil, &KindError{Kind: provider.Name, Namespace: namespace, Err: err}
+		}
+		for _, victim := range found {
+			if victim.Name() == name {
+				victim.SetCluster(cluster)
+				return victim, nil
+			}
+		}
+		return nil, fmt.Errorf("%s %s/%s is not enrolled in kube-monkey", kind, namespace, name)
+	}
+
+	return nil, fmt.Errorf("victim kind %s is not enabled", kind)
+}
+
+// Lists the eligible victims of all providers in the namespaces.
+// With config.ClusterWideDiscovery, the victims of several namespaces
+// are listed once per provider across the cluster and filtered locally.
+// Otherwise the providers are queried for each namespace. The providers
+// are queried in parallel, and the failure of one provider does not
+// affect the others
+func eligibleVictimsOf(client victims.VictimKubeClient, providers []Provider, namespaces []string, filter *metav1.ListOptions) (eligibleVictims []victims.Victim, kindErrs []*KindError) {
+	if config.ClusterWideDiscovery() && len(namespaces) > 1 {
+		targeted := sets.NewString(namespaces...)
+		found, errs := listProviders(client, providers, metav1.NamespaceAll, filter)
+		for _, victim := range found {
+			if targeted.Has(victim.Namespace()) {
+				eligibleVictims = append(eligibleVictims, victim)
+			}
 		}
-		eligibleVictims = append(eligibleVictims, daemonsets...)
+		return eligibleVictims, errs
+	}
+
+	for _, namespace := range namespaces {
+		found, errs := listProviders(client, providers, namespace, filter)
+		eligibleVictims = append(eligibleVictims, found...)
+		kindErrs = append(kindErrs, errs...)
+	}
+
+	return
+}
+
+// Lists the eligible victims of all providers in the namespace in parallel
+func listProviders(client victims.VictimKubeClient, providers []Provider, namespace string, filter *metav1.ListOptions) (eligibleVictims []victims.Victim, kindErrs []*KindError) {
+	results := make([][]victims.Victim, len(providers))
+	errs := make([]*KindError, len(providers))
 
-		// Fetch CNPG clusters if CRD is available
-		if eligible := clusters.IsEligible(dynamicClient); eligible {
-			cnpgClusters, err := clusters.EligibleClusters(dynamicClient, namespace, filter)
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			found, err := provider.List(client, namespace, filter)
 			if err != nil {
-				glog.Warningf("Failed to fetch eligible CNPG clusters for namespace %s due to error: %s", namespace, err.Error())
-			} else {
-				eligibleVictims = append(eligibleVictims, cnpgClusters...)
+				errs[i] = &KindError{Kind: provider.Name, Namespace: namespace, Err: err}
+				return
 			}
+			results[i] = found
+		}(i, provider)
+	}
+	wg.Wait()
+
+	// Gather in registration order to keep the schedule stable
+	for i := range providers {
+		if errs[i] != nil {
+			kindErrs = append(kindErrs, errs[i])
+			continue
 		}
+		eligibleVictims = append(eligibleVictims, results[i]...)
 	}
 
 	return
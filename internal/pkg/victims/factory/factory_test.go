@@ -0,0 +1,207 @@
+package factory
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"kube-monkey/internal/pkg/calendar"
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/kubernetes"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newProvider(name string, available bool, err error) Provider {
+	return Provider{
+		Name: name,
+		Available: func(victims.VictimKubeClient) bool {
+			return available
+		},
+		List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+			if err != nil {
+				return nil, err
+			}
+			return []victims.Victim{newVictimOf(name, namespace)}, nil
+		},
+	}
+}
+
+// victimOf satisfies victims.Victim through the embedded VictimBase
+type victimOf struct {
+	*victims.VictimBase
+	victims.VictimSpecificAPICalls
+}
+
+func newVictimOf(name, namespace string) victims.Victim {
+	return &victimOf{VictimBase: victims.New(name, name, namespace, name, calendar.Day)}
+}
+
+func TestEligibleVictimsOfIsolatesKindErrors(t *testing.T) {
+	viper.Set(param.ClusterWideDiscovery, false)
+	defer viper.Set(param.ClusterWideDiscovery, true)
+
+	client := victims.NewVictimClient(fake.NewSimpleClientset(), nil)
+	providers := []Provider{
+		newProvider("first", true, nil),
+		newProvider("failing", true, errors.New("boom")),
+		newProvider("last", true, nil),
+	}
+
+	found, kindErrs := eligibleVictimsOf(client, providers, []string{"ns1", "ns2"}, &metav1.ListOptions{})
+
+	assert.Len(t, found, 4, "Expected victims of the other kinds despite the failing kind")
+	assert.Equal(t, "first", found[0].Kind())
+	assert.Equal(t, "last", found[1].Kind())
+	assert.Equal(t, "ns2", found[2].Namespace())
+
+	assert.Len(t, kindErrs, 2)
+	assert.EqualError(t, kindErrs[0], "failed to fetch eligible failing for namespace ns1: boom")
+}
+
+func TestEligibleVictimsOfClusterWide(t *testing.T) {
+	viper.Set(param.ClusterWideDiscovery, true)
+
+	var listed []string
+	var mu sync.Mutex
+	provider := Provider{
+		Name: "clusterwide",
+		List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+			mu.Lock()
+			listed = append(listed, namespace)
+			mu.Unlock()
+			return []victims.Victim{
+				newVictimOf("clusterwide", "ns1"),
+				newVictimOf("clusterwide", "ns2"),
+				newVictimOf("clusterwide", "ns3"),
+			}, nil
+		},
+	}
+	client := victims.NewVictimClient(fake.NewSimpleClientset(), nil)
+
+	found, kindErrs := eligibleVictimsOf(client, []Provider{provider, newProvider("failing", true, errors.New("boom"))}, []string{"ns1", "ns2"}, &metav1.ListOptions{})
+
+	assert.Equal(t, []string{metav1.NamespaceAll}, listed, "Expected a single cluster-wide List")
+	assert.Len(t, found, 2, "Expected the victims of the other namespaces to be filtered out")
+	assert.Equal(t, "ns1", found[0].Namespace())
+	assert.Equal(t, "ns2", found[1].Namespace())
+	assert.Len(t, kindErrs, 1)
+}
+
+func TestEnabledProviders(t *testing.T) {
+	config.SetDefaults()
+	viper.Set(param.DisabledVictimKinds, []string{"disabled"})
+	defer viper.Set(param.DisabledVictimKinds, []string{})
+
+	client := victims.NewVictimClient(fake.NewSimpleClientset(), nil)
+	providers := []Provider{
+		newProvider("enabled", true, nil),
+		newProvider("disabled", true, nil),
+		newProvider("unavailable", false, nil),
+		{Name: "always", List: newProvider("always", true, nil).List},
+	}
+
+	var skipped []victims.SkippedVictim
+	remove := victims.OnSkip(func(victim victims.SkippedVictim) { skipped = append(skipped, victim) })
+	defer remove()
+
+	enabled := EnabledProviders(client, providers)
+
+	assert.Len(t, enabled, 2)
+	assert.Equal(t, "enabled", enabled[0].Name)
+	assert.Equal(t, "always", enabled[1].Name)
+	assert.Equal(t, []victims.SkippedVictim{{Kind: "unavailable", Reason: "not served by the apiserver"}}, skipped, "Expected the unavailable kind to be reported")
+}
+
+func TestProviders(t *testing.T) {
+	config.SetDefaults()
+	viper.Set(param.CustomResources, []map[string]interface{}{
+		{"group": "example.com", "version": "v1", "resource": "widgets"},
+	})
+	defer viper.Set(param.CustomResources, []config.CustomResource{})
+
+	builtin := append([]Provider{}, registry...)
+	defer func() { registry = builtin }()
+
+	Register(newProvider("deployments", true, nil))
+	providers := Providers()
+
+	assert.Len(t, providers, len(builtin)+1, "Expected registering an existing kind to replace it")
+	assert.Equal(t, "deployments", providers[0].Name)
+	assert.Equal(t, "widgets.example.com", providers[len(builtin)].Name)
+}
+
+func TestFindVictim(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	enrolled := map[string]string{config.EnabledLabelKey: config.EnabledLabelValue, config.MtbfLabelKey: "1"}
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app", Labels: enrolled}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app", Labels: enrolled}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "app"}},
+	)
+	clients := kubernetes.NewStaticClientProvider(clientset, nil)
+
+	victim, err := FindVictim("staging", clients, "deployments", "app", "web")
+	assert.NoError(t, err)
+	assert.Equal(t, "web", victim.Name())
+	assert.Equal(t, "staging", victim.Cluster())
+
+	_, err = FindVictim("", clients, "deployments", "app", "worker")
+	assert.EqualError(t, err, "deployments app/worker is not enrolled in kube-monkey")
+
+	viper.Set(param.DisabledVictimKinds, []string{"deployments"})
+	_, err = FindVictim("", clients, "deployments", "app", "web")
+	assert.EqualError(t, err, "victim kind deployments is not enabled")
+
+	_, err = FindVictim("", clients, "unknown", "app", "web")
+	assert.EqualError(t, err, "victim kind unknown is not enabled")
+}
+
+func TestFindVictimNamespaceSelector(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+	defer victims.SetNamespaceLabels("staging", nil)
+
+	enrolled := map[string]string{config.EnabledLabelKey: config.EnabledLabelValue, config.MtbfLabelKey: "1"}
+	clientset := fake.NewSimpleClientset(
+		newNamespace("payments", map[string]string{"tier": "critical"}),
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "payments", Labels: enrolled}},
+	)
+	clients := kubernetes.NewStaticClientProvider(clientset, nil)
+
+	victim, err := FindVictim("staging", clients, "deployments", "payments", "web")
+	assert.NoError(t, err)
+	assert.False(t, victim.IsBlacklisted())
+
+	viper.Set(param.BlacklistedNamespaceSelector, "tier=critical")
+
+	victim, err = FindVictim("staging", clients, "deployments", "payments", "web")
+	assert.NoError(t, err)
+	assert.True(t, victim.IsBlacklisted(), "Expected the labels of the namespace to be matched against the blacklist")
+
+	_, err = FindVictim("staging", clients, "deployments", "missing", "web")
+	assert.Error(t, err, "Expected an error if the namespace does not exist")
+}
+
+func TestResolveKind(t *testing.T) {
+	assert.Equal(t, "deployments", ResolveKind("deployments"))
+	assert.Equal(t, "deployments", ResolveKind("Deployment"))
+	assert.Equal(t, "statefulsets", ResolveKind("statefulset"))
+	assert.Equal(t, "rollouts.argoproj.io", ResolveKind("rollout.argoproj.io"))
+	assert.Equal(t, "clusters.postgresql.cnpg.io", ResolveKind("cluster.postgresql.cnpg.io"))
+	assert.Equal(t, "cluster", ResolveKind("cluster"), "Expected a kind without its group to be unknown")
+	assert.Equal(t, "widgets", ResolveKind("widgets"))
+}
@@ -0,0 +1,145 @@
+package jobs
+
+//All these functions require api access specific to the version of the app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/victims"
+
+	kube "k8s.io/client-go/kubernetes"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// PollInterval is the interval at which the status of a job
+// is checked while waiting for it to finish
+var PollInterval = 5 * time.Second
+
+// EligibleJobs gets all eligible standalone jobs that opted in (filtered by config.EnabledLabel)
+// Jobs controlled by a CronJob or another workload are skipped,
+// as their controller is the victim
+func EligibleJobs(clientset kube.Interface, namespace string, filter *metav1.ListOptions) (eligVictims []victims.Victim, err error) {
+	enabledVictims, err := clientset.BatchV1().Jobs(namespace).List(context.TODO(), *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vic := range enabledVictims.Items {
+		if controller := metav1.GetControllerOf(&vic); controller != nil {
+			glog.V(4).Infof("Skipping eligible %s %s because it is controlled by %s %s", kind, vic.Name, controller.Kind, controller.Name)
+			continue
+		}
+
+		victim, err := New(&vic)
+		if err != nil {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", kind, vic.Name, err.Error())
+			victims.ReportSkipped(kind, vic.Namespace, vic.Name, err.Error())
+			continue
+		}
+
+		eligVictims = append(eligVictims, victim)
+	}
+
+	return
+}
+
+// Returns the job of the victim, looked up once per attack
+func (j *Job) get(client victims.VictimKubeClient) (*batchv1.Job, error) {
+	return victims.Lookup(client, j, func() (*batchv1.Job, error) {
+		return client.Kube().BatchV1().Jobs(j.Namespace()).Get(context.TODO(), j.Name(), metav1.GetOptions{})
+	})
+}
+
+/* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
+
+// IsEnrolled checks if the job is currently enrolled in kube-monkey
+func (j *Job) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
+	job, err := j.get(client)
+	if err != nil {
+		return false, err
+	}
+	return j.EnrolledBy(job.GetLabels()), nil
+}
+
+// KillType returns current killtype config label for update
+func (j *Job) KillType(client victims.VictimKubeClient) (string, error) {
+	job, err := j.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	return j.KillTypeFrom(job.GetLabels())
+}
+
+// KillValue returns current killvalue config label for update
+func (j *Job) KillValue(client victims.VictimKubeClient) (int, error) {
+	job, err := j.get(client)
+	if err != nil {
+		return -1, err
+	}
+
+	return j.KillValueFrom(job.GetLabels())
+}
+
+// Annotate merges the annotations into the job
+func (j *Job) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(j, annotations, client.Kube().BatchV1().Jobs(j.Namespace()).Patch)
+}
+
+// VerifyRecovery waits for the job to finish, and returns an error
+// if it failed or did not finish within timeout
+func (j *Job) VerifyRecovery(client victims.VictimKubeClient, attackedAt time.Time, timeout time.Duration) error {
+	var job *batchv1.Job
+	err := wait.PollUntilContextTimeout(context.TODO(), PollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var err error
+		job, err = client.Kube().BatchV1().Jobs(j.Namespace()).Get(ctx, j.Name(), metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		_, finished := FinishedCondition(job)
+		return finished, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s did not finish within %s: %v", j.Kind(), j.Name(), timeout, err)
+	}
+
+	if condition, _ := FinishedCondition(job); condition.Type == batchv1.JobFailed {
+		return fmt.Errorf("%s %s failed: %s %s", j.Kind(), j.Name(), condition.Reason, condition.Message)
+	}
+	return nil
+}
+
+// FinishedCondition returns the Complete or Failed condition of the job,
+// and whether the job has finished
+func FinishedCondition(job *batchv1.Job) (batchv1.JobCondition, bool) {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return condition, true
+		}
+	}
+	return batchv1.JobCondition{}, false
+}
+
+// Returns the active pods of the job, found through its selector
+// and verified through their owner references
+func (j *Job) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	job, err := j.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	// A finished job has no active pods left to attack
+	if _, finished := FinishedCondition(job); finished {
+		return nil, nil
+	}
+
+	return victims.PodsControlledBy(client, j.Namespace(), job.Spec.Selector, job.UID)
+}
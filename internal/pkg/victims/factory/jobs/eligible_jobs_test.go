@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newRunningJob(conditions ...batchv1.JobCondition) (*batchv1.Job, *corev1.Pod) {
+	controller := true
+	selector := map[string]string{"batch.kubernetes.io/controller-uid": "job-uid"}
+
+	v1job := newJob(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	v1job.UID = "job-uid"
+	v1job.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+	v1job.Status.Conditions = conditions
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            NAME + "-1",
+			Namespace:       NAMESPACE,
+			Labels:          selector,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: NAME, UID: v1job.UID, Controller: &controller}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	return &v1job, pod
+}
+
+func TestEligibleJobs(t *testing.T) {
+	v1job := newJob(
+		NAME,
+		map[string]string{
+			config.MtbfLabelKey: "1",
+		},
+	)
+
+	client := fake.NewSimpleClientset(&v1job)
+	victims, _ := EligibleJobs(client, NAMESPACE, &metav1.ListOptions{})
+
+	assert.Len(t, victims, 1)
+}
+
+func TestEligibleJobsSkipsControlled(t *testing.T) {
+	controller := true
+	standalone := newJob(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	scheduled := newJob(NAME+"-28000000", map[string]string{config.MtbfLabelKey: "1"})
+	scheduled.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: NAME, UID: "cronjob-uid", Controller: &controller}}
+
+	client := fake.NewSimpleClientset(&standalone, &scheduled)
+	eligible, _ := EligibleJobs(client, NAMESPACE, &metav1.ListOptions{})
+
+	if assert.Len(t, eligible, 1, "Expected the job of the cronjob to be skipped") {
+		assert.Equal(t, NAME, eligible[0].Name())
+	}
+}
+
+func TestPods(t *testing.T) {
+	v1job, pod := newRunningJob()
+	job, _ := New(v1job)
+
+	client := victims.NewVictimClient(fake.NewSimpleClientset(v1job, pod), nil)
+	pods, err := job.RunningPods(client)
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+
+	v1job, pod = newRunningJob(batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue})
+	client = victims.NewVictimClient(fake.NewSimpleClientset(v1job, pod), nil)
+	pods, err = job.RunningPods(client)
+
+	assert.NoError(t, err)
+	assert.Empty(t, pods, "Expected no active pods for a finished job")
+}
+
+func TestVerifyRecovery(t *testing.T) {
+	pollInterval := PollInterval
+	t.Cleanup(func() { PollInterval = pollInterval })
+	PollInterval = time.Millisecond
+
+	v1job, _ := newRunningJob(batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue})
+	job, _ := New(v1job)
+	client := victims.NewVictimClient(fake.NewSimpleClientset(v1job), nil)
+	assert.NoError(t, job.VerifyRecovery(client, time.Now(), time.Second))
+
+	v1job, _ = newRunningJob(batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"})
+	client = victims.NewVictimClient(fake.NewSimpleClientset(v1job), nil)
+	assert.EqualError(t, job.VerifyRecovery(client, time.Now(), time.Second), "v1.Job "+NAME+" failed: BackoffLimitExceeded ")
+
+	v1job, _ = newRunningJob()
+	client = victims.NewVictimClient(fake.NewSimpleClientset(v1job), nil)
+	assert.Error(t, job.VerifyRecovery(client, time.Now(), 10*time.Millisecond), "Expected an error for a job that does not finish in time")
+}
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+type Job struct {
+	*victims.VictimBase
+}
+
+// Kind of the jobs, i.e. v1.Job
+var kind = fmt.Sprintf("%T", batchv1.Job{})
+
+// New creates a new instance of Job
+func New(job *batchv1.Job) (*Job, error) {
+	ident := identifier(job)
+	mtbf, err := meanTimeBetweenFailures(job)
+	if err != nil {
+		return nil, err
+	}
+
+	base := victims.New(kind, job.Name, job.Namespace, ident, mtbf)
+	base.ReadHistory(job)
+
+	victim := &Job{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
+}
+
+// Returns the value of the label defined by config.IdentLabelKey
+// from the job labels, if any
+// The label is optional, as the pods that belong to this job
+// are found through its selector and verified through owner references
+func identifier(kubekind *batchv1.Job) string {
+	return kubekind.Labels[config.IdentLabelKey]
+}
+
+// Read the mean-time-between-failures value defined by the Job
+// in the label defined by config.MtbfLabelKey
+func meanTimeBetweenFailures(kubekind *batchv1.Job) (time.Duration, error) {
+	mtbf, ok := kubekind.Labels[config.MtbfLabelKey]
+	if !ok {
+		return -1, fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.MtbfLabelKey)
+	}
+
+	return victims.ParseMtbf(mtbf)
+}
@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"testing"
+
+	"kube-monkey/internal/pkg/calendar"
+	"kube-monkey/internal/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	IDENTIFIER = "kube-monkey-id"
+	NAME       = "job_name"
+	NAMESPACE  = metav1.NamespaceDefault
+)
+
+func newJob(name string, labels map[string]string) batchv1.Job {
+
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: NAMESPACE,
+			Labels:    labels,
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	v1job := newJob(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "1",
+		},
+	)
+	job, err := New(&v1job)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.Job", job.Kind())
+	assert.Equal(t, NAME, job.Name())
+	assert.Equal(t, NAMESPACE, job.Namespace())
+	assert.Equal(t, IDENTIFIER, job.Identifier())
+	assert.Equal(t, calendar.Day, job.Mtbf())
+}
+
+func TestInvalidMtbf(t *testing.T) {
+	v1job := newJob(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+		},
+	)
+	_, err := New(&v1job)
+
+	assert.Errorf(t, err, "Expected an error if "+config.MtbfLabelKey+" label doesn't exist")
+}
@@ -0,0 +1,83 @@
+package factory
+
+import (
+	"context"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+)
+
+// Resolves the namespaces of the cluster to look for victims in
+// Whitelists and blacklists of namespace names are checked without
+// listing the namespaces, as before, and "" stands for all of them.
+// Patterns and label selectors require the namespaces to be listed,
+// in which case their labels are also cached for the victims to check
+// themselves against the blacklist
+func targetNamespaces(cluster string, client kube.Interface) ([]string, error) {
+	whitelist, err := config.NamespaceWhitelistIn(cluster)
+	if err != nil {
+		return nil, err
+	}
+	blacklist, err := config.NamespaceBlacklistIn(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	_, whitelistLiteral := whitelist.Names()
+	_, blacklistLiteral := blacklist.Names()
+	if whitelistLiteral && blacklistLiteral {
+		return config.WhitelistedNamespacesIn(cluster).List(), nil
+	}
+
+	nsList, err := client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(map[string]map[string]string, len(nsList.Items))
+	var namespaces []string
+	for _, ns := range nsList.Items {
+		labels[ns.Name] = ns.Labels
+		if config.WhitelistEnabledIn(cluster) && !whitelist.Matches(ns.Name, ns.Labels) {
+			continue
+		}
+		if config.BlacklistEnabledIn(cluster) && blacklist.Matches(ns.Name, ns.Labels) {
+			continue
+		}
+		namespaces = append(namespaces, ns.Name)
+	}
+	victims.SetNamespaceLabels(cluster, labels)
+
+	return namespaces, nil
+}
+
+// Caches the labels of the namespace of a victim found on its own, e.g. the
+// target of an experiment, for the victim to check itself against the
+// whitelist and blacklist. As in targetNamespaces, the labels are only
+// needed for patterns and label selectors
+func recordNamespaceLabels(cluster string, client kube.Interface, namespace string) error {
+	whitelist, err := config.NamespaceWhitelistIn(cluster)
+	if err != nil {
+		return err
+	}
+	blacklist, err := config.NamespaceBlacklistIn(cluster)
+	if err != nil {
+		return err
+	}
+
+	_, whitelistLiteral := whitelist.Names()
+	_, blacklistLiteral := blacklist.Names()
+	if whitelistLiteral && blacklistLiteral {
+		return nil
+	}
+
+	ns, err := client.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	victims.SetNamespaceLabelsOf(cluster, namespace, ns.Labels)
+	return nil
+}
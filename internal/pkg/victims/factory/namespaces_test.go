@@ -0,0 +1,101 @@
+package factory
+
+import (
+	"testing"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestTargetNamespacesLiteral(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	client := fake.NewSimpleClientset(newNamespace("ns1", nil))
+
+	namespaces, err := targetNamespaces("", client)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{metav1.NamespaceAll}, namespaces, "Expected all namespaces without listing them")
+
+	viper.Set(param.WhitelistedNamespaces, []string{"ns2", "ns1"})
+	namespaces, err = targetNamespaces("", client)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns1", "ns2"}, namespaces)
+}
+
+func TestTargetNamespacesPatternsAndSelectors(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+	defer victims.SetNamespaceLabels("", nil)
+
+	client := fake.NewSimpleClientset(
+		newNamespace("team-a", nil),
+		newNamespace("team-b", map[string]string{"tier": "critical"}),
+		newNamespace("checkout", map[string]string{"env": "staging"}),
+		newNamespace("other", nil),
+		newNamespace(metav1.NamespaceSystem, nil),
+	)
+
+	viper.Set(param.WhitelistedNamespaces, []string{"team-*"})
+	viper.Set(param.WhitelistedNamespaceSelector, "env=staging")
+	viper.Set(param.BlacklistedNamespaceSelector, "tier=critical")
+
+	namespaces, err := targetNamespaces("", client)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"team-a", "checkout"}, namespaces)
+	assert.Equal(t, map[string]string{"tier": "critical"}, victims.NamespaceLabels("", "team-b"), "Expected the namespace labels to be cached")
+
+	viper.Set(param.WhitelistedNamespaces, []string{metav1.NamespaceAll})
+	viper.Set(param.WhitelistedNamespaceSelector, "")
+	namespaces, err = targetNamespaces("", client)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"team-a", "checkout", "other"}, namespaces, "Expected all namespaces but the blacklisted ones")
+}
+
+func TestTargetNamespacesInCluster(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+	defer victims.SetNamespaceLabels("staging", nil)
+
+	viper.Set(param.Clusters, []map[string]interface{}{
+		{"name": "staging", "whitelisted_namespaces": []string{"team-*"}},
+	})
+	client := fake.NewSimpleClientset(
+		newNamespace("team-a", map[string]string{"env": "staging"}),
+		newNamespace("other", nil),
+	)
+
+	namespaces, err := targetNamespaces("staging", client)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"team-a"}, namespaces)
+	assert.Equal(t, map[string]string{"env": "staging"}, victims.NamespaceLabels("staging", "team-a"))
+	assert.Nil(t, victims.NamespaceLabels("", "team-a"), "Expected the labels to be cached by cluster")
+
+	namespaces, err = targetNamespaces("", client)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{metav1.NamespaceAll}, namespaces, "Expected the global namespaces outside of the cluster")
+}
+
+func TestTargetNamespacesInvalid(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer viper.Reset()
+
+	viper.Set(param.WhitelistedNamespaceSelector, "env in (")
+	_, err := targetNamespaces("", fake.NewSimpleClientset())
+	assert.Error(t, err)
+}
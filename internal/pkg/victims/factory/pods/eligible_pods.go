@@ -0,0 +1,97 @@
+package pods
+
+//All these functions require api access specific to the version of the app
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/victims"
+
+	kube "k8s.io/client-go/kubernetes"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EligiblePods gets all eligible standalone pods that opted in (filtered by config.EnabledLabel)
+// Pods with a controller are skipped, as their controller is the victim,
+// e.g. a ReplicaSet or the custom resource of an operator
+func EligiblePods(clientset kube.Interface, namespace string, filter *metav1.ListOptions) (eligVictims []victims.Victim, err error) {
+	enabledVictims, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vic := range enabledVictims.Items {
+		if controller := metav1.GetControllerOf(&vic); controller != nil {
+			glog.V(4).Infof("Skipping eligible %s %s because it is controlled by %s %s", kind, vic.Name, controller.Kind, controller.Name)
+			continue
+		}
+
+		victim, err := New(&vic)
+		if err != nil {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", kind, vic.Name, err.Error())
+			victims.ReportSkipped(kind, vic.Namespace, vic.Name, err.Error())
+			continue
+		}
+
+		eligVictims = append(eligVictims, victim)
+	}
+
+	return
+}
+
+// Returns the pod of the victim, looked up once per attack
+func (p *Pod) get(client victims.VictimKubeClient) (*corev1.Pod, error) {
+	return victims.Lookup(client, p, func() (*corev1.Pod, error) {
+		return client.Kube().CoreV1().Pods(p.Namespace()).Get(context.TODO(), p.Name(), metav1.GetOptions{})
+	})
+}
+
+/* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
+
+// IsEnrolled checks if the pod is currently enrolled in kube-monkey
+func (p *Pod) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
+	pod, err := p.get(client)
+	if err != nil {
+		return false, err
+	}
+	return p.EnrolledBy(pod.GetLabels()), nil
+}
+
+// KillType returns current killtype config label for update
+func (p *Pod) KillType(client victims.VictimKubeClient) (string, error) {
+	pod, err := p.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	return p.KillTypeFrom(pod.GetLabels())
+}
+
+// KillValue returns current killvalue config label for update
+func (p *Pod) KillValue(client victims.VictimKubeClient) (int, error) {
+	pod, err := p.get(client)
+	if err != nil {
+		return -1, err
+	}
+
+	return p.KillValueFrom(pod.GetLabels())
+}
+
+// Annotate merges the annotations into the pod
+func (p *Pod) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(p, annotations, client.Kube().CoreV1().Pods(p.Namespace()).Patch)
+}
+
+// Returns the pod itself
+func (p *Pod) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	pod, err := p.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return []corev1.Pod{*pod}, nil
+}
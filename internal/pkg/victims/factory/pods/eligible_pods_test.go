@@ -0,0 +1,78 @@
+package pods
+
+import (
+	"context"
+	"testing"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// The kube-monkey labels are read by victims.VictimBase, and tested there
+
+func TestEligiblePods(t *testing.T) {
+	labels := map[string]string{config.MtbfLabelKey: "1"}
+	controller := true
+
+	standalone := newPod(NAME, labels)
+	controlled := newPod("controlled_pod", labels)
+	controlled.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "replicaset_name", UID: "replicaset-uid", Controller: &controller}}
+	// Pods of an operator are attacked through its custom resource
+	operated := newPod("operated_pod", labels)
+	operated.OwnerReferences = []metav1.OwnerReference{{Kind: "Cluster", Name: "cluster_name", UID: "cluster-uid", Controller: &controller}}
+
+	client := fake.NewSimpleClientset(&standalone, &controlled, &operated)
+	victims, err := EligiblePods(client, NAMESPACE, &metav1.ListOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, victims, 1, "Expected pods with a controller to be skipped")
+	assert.Equal(t, standalone.Name, victims[0].Name())
+}
+
+func TestIsEnrolled(t *testing.T) {
+	v1pod := newPod(NAME, map[string]string{
+		config.MtbfLabelKey:    "1",
+		config.EnabledLabelKey: config.EnabledLabelValue,
+	})
+	pod, _ := New(&v1pod)
+
+	b, err := pod.IsEnrolled(victims.NewVictimClient(fake.NewSimpleClientset(&v1pod), nil))
+	assert.NoError(t, err)
+	assert.True(t, b, "Expected pod to be enrolled")
+
+	v1pod.Labels[config.EnabledLabelKey] = "x"
+	b, err = pod.IsEnrolled(victims.NewVictimClient(fake.NewSimpleClientset(&v1pod), nil))
+	assert.NoError(t, err)
+	assert.False(t, b, "Expected pod to not be enrolled")
+}
+
+func TestAnnotate(t *testing.T) {
+	v1pod := newPod(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	pod, _ := New(&v1pod)
+	client := fake.NewSimpleClientset(&v1pod)
+
+	err := pod.Annotate(victims.NewVictimClient(client, nil), map[string]string{config.LastKilledAnnotationKey: "2024-01-01T10:00:00Z"})
+	assert.NoError(t, err)
+
+	annotated, _ := client.CoreV1().Pods(NAMESPACE).Get(context.TODO(), NAME, metav1.GetOptions{})
+	assert.Equal(t, "2024-01-01T10:00:00Z", annotated.Annotations[config.LastKilledAnnotationKey])
+}
+
+func TestPods(t *testing.T) {
+	v1pod := newPod(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	other := newPod("other_pod", map[string]string{config.MtbfLabelKey: "1"})
+
+	pod, _ := New(&v1pod)
+
+	client := fake.NewSimpleClientset(&v1pod, &other)
+
+	pods, err := pod.Pods(victims.NewVictimClient(client, nil))
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, v1pod.Name, pods[0].Name)
+}
@@ -0,0 +1,59 @@
+package pods
+
+import (
+	"fmt"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+type Pod struct {
+	*victims.VictimBase
+}
+
+// Kind of the pods, i.e. v1.Pod
+var kind = fmt.Sprintf("%T", corev1.Pod{})
+
+// New creates a new instance of Pod
+func New(pod *corev1.Pod) (*Pod, error) {
+	ident := identifier(pod)
+	mtbf, err := meanTimeBetweenFailures(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	base := victims.New(kind, pod.Name, pod.Namespace, ident, mtbf)
+	base.ReadHistory(pod)
+
+	victim := &Pod{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
+}
+
+// DeletesObject checks if killing the victim deletes its object,
+// which is always the case as the pod is its own and only pod
+func (p *Pod) DeletesObject() bool {
+	return true
+}
+
+// Returns the value of the label defined by config.IdentLabelKey
+// from the pod labels, if any
+// The label is optional, as the pod is its own and only pod
+func identifier(kubekind *corev1.Pod) string {
+	return kubekind.Labels[config.IdentLabelKey]
+}
+
+// Read the mean-time-between-failures value defined by the Pod
+// in the label defined by config.MtbfLabelKey
+func meanTimeBetweenFailures(kubekind *corev1.Pod) (time.Duration, error) {
+	mtbf, ok := kubekind.Labels[config.MtbfLabelKey]
+	if !ok {
+		return -1, fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.MtbfLabelKey)
+	}
+
+	return victims.ParseMtbf(mtbf)
+}
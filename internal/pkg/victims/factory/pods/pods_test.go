@@ -0,0 +1,110 @@
+package pods
+
+import (
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/calendar"
+	"kube-monkey/internal/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	IDENTIFIER = "kube-monkey-id"
+	NAME       = "pod_name"
+	NAMESPACE  = metav1.NamespaceDefault
+)
+
+func newPod(name string, labels map[string]string) corev1.Pod {
+
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: NAMESPACE,
+			Labels:    labels,
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	v1pod := newPod(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "1",
+		},
+	)
+	pod, err := New(&v1pod)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.Pod", pod.Kind())
+	assert.Equal(t, NAME, pod.Name())
+	assert.Equal(t, NAMESPACE, pod.Namespace())
+	assert.Equal(t, IDENTIFIER, pod.Identifier())
+	assert.Equal(t, calendar.Day, pod.Mtbf())
+}
+
+func TestNewDurationMtbf(t *testing.T) {
+	v1pod := newPod(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "4h",
+		},
+	)
+	pod, err := New(&v1pod)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4*time.Hour, pod.Mtbf())
+}
+
+func TestOptionalIdentifier(t *testing.T) {
+	v1pod := newPod(
+		NAME,
+		map[string]string{
+			config.MtbfLabelKey: "1",
+		},
+	)
+	victim, err := New(&v1pod)
+
+	assert.NoError(t, err, "Expected "+config.IdentLabelKey+" label to be optional")
+	assert.Empty(t, victim.Identifier())
+}
+
+func TestInvalidMtbf(t *testing.T) {
+	v1pod := newPod(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+		},
+	)
+	_, err := New(&v1pod)
+
+	assert.Errorf(t, err, "Expected an error if "+config.MtbfLabelKey+" label doesn't exist")
+
+	v1pod = newPod(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "string",
+		},
+	)
+	_, err = New(&v1pod)
+
+	assert.Errorf(t, err, "Expected an error if "+config.MtbfLabelKey+" label can't be converted a Int type")
+
+	v1pod = newPod(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "0",
+		},
+	)
+	_, err = New(&v1pod)
+
+	assert.Errorf(t, err, "Expected an error if "+config.MtbfLabelKey+" label is lower than 1")
+}
@@ -0,0 +1,182 @@
+package factory
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+	"kube-monkey/internal/pkg/victims/factory/argoproj.io/rollouts"
+	"kube-monkey/internal/pkg/victims/factory/cnpg.io/postgresql/clusters"
+	"kube-monkey/internal/pkg/victims/factory/cronjobs"
+	"kube-monkey/internal/pkg/victims/factory/custom"
+	"kube-monkey/internal/pkg/victims/factory/daemonsets"
+	"kube-monkey/internal/pkg/victims/factory/deployments"
+	"kube-monkey/internal/pkg/victims/factory/jobs"
+	"kube-monkey/internal/pkg/victims/factory/pods"
+	"kube-monkey/internal/pkg/victims/factory/replicasets"
+	"kube-monkey/internal/pkg/victims/factory/statefulsets"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Provider discovers the eligible victims of one kind
+type Provider struct {
+	// Name identifies the kind in config.DisabledVictimKinds
+	Name string
+
+	// Available checks if the kind is served by the apiserver
+	// A nil Available means the kind is always available
+	Available func(victims.VictimKubeClient) bool
+
+	// List returns the eligible victims of the kind in a namespace
+	List func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = []Provider{
+		{
+			Name: "deployments",
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return deployments.EligibleDeployments(client.Kube(), namespace, filter)
+			},
+		},
+		{
+			Name: "statefulsets",
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return statefulsets.EligibleStatefulSets(client.Kube(), namespace, filter)
+			},
+		},
+		{
+			Name: "daemonsets",
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return daemonsets.EligibleDaemonSets(client.Kube(), namespace, filter)
+			},
+		},
+		{
+			Name: "jobs",
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return jobs.EligibleJobs(client.Kube(), namespace, filter)
+			},
+		},
+		{
+			Name: "cronjobs",
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return cronjobs.EligibleCronJobs(client.Kube(), namespace, filter)
+			},
+		},
+		{
+			Name: "replicasets",
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return replicasets.EligibleReplicaSets(client.Kube(), namespace, filter)
+			},
+		},
+		{
+			Name: "pods",
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return pods.EligiblePods(client.Kube(), namespace, filter)
+			},
+		},
+		{
+			Name: "clusters.postgresql.cnpg.io",
+			Available: func(client victims.VictimKubeClient) bool {
+				return clusters.IsEligible(client.Dynamic())
+			},
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return clusters.EligibleClusters(client.Dynamic(), namespace, filter)
+			},
+		},
+		{
+			Name: "rollouts.argoproj.io",
+			Available: func(client victims.VictimKubeClient) bool {
+				return rollouts.IsEligible(client.Dynamic())
+			},
+			List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+				return rollouts.EligibleRollouts(client.Dynamic(), namespace, filter)
+			},
+		},
+	}
+)
+
+// Register adds a victim kind to the registry
+// A provider with the same name as a registered one replaces it
+func Register(provider Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i := range registry {
+		if registry[i].Name == provider.Name {
+			registry[i] = provider
+			return
+		}
+	}
+	registry = append(registry, provider)
+}
+
+// Providers returns the registered victim kinds followed by
+// the custom resources listed in config.CustomResources
+func Providers() []Provider {
+	registryMu.Lock()
+	providers := make([]Provider, len(registry))
+	copy(providers, registry)
+	registryMu.Unlock()
+
+	for _, resource := range config.CustomResources() {
+		providers = append(providers, customProvider(resource))
+	}
+	return providers
+}
+
+// ResolveKind returns the name of the provider of the kind, which may
+// be singular or plural, e.g. "deployment" or "rollout.argoproj.io".
+// Unknown kinds are returned as is
+func ResolveKind(kind string) string {
+	kind = strings.ToLower(kind)
+	resource, group, _ := strings.Cut(kind, ".")
+	for _, provider := range Providers() {
+		if provider.Name == kind {
+			return provider.Name
+		}
+		name, providerGroup, _ := strings.Cut(provider.Name, ".")
+		if providerGroup == group && name == resource+"s" {
+			return provider.Name
+		}
+	}
+	return kind
+}
+
+// EnabledProviders returns the victim kinds that are not disabled
+// in config.DisabledVictimKinds and are served by the apiserver
+// The kinds that are not served are logged and reported as skipped
+func EnabledProviders(client victims.VictimKubeClient, providers []Provider) (enabled []Provider) {
+	disabled := config.DisabledVictimKinds()
+	for _, provider := range providers {
+		if disabled.Has(provider.Name) {
+			continue
+		}
+		if provider.Available != nil && !provider.Available(client) {
+			glog.Warningf("Skipping victim kind %s as it is not served by the apiserver", provider.Name)
+			victims.ReportSkipped(provider.Name, "", "", "not served by the apiserver")
+			continue
+		}
+		enabled = append(enabled, provider)
+	}
+	return
+}
+
+// Creates the provider for a custom resource listed in config.CustomResources
+func customProvider(resource config.CustomResource) Provider {
+	gvr := resource.GroupVersionResource()
+	return Provider{
+		Name: gvr.GroupResource().String(),
+		Available: func(client victims.VictimKubeClient) bool {
+			return custom.IsEligible(client.Dynamic(), gvr)
+		},
+		List: func(client victims.VictimKubeClient, namespace string, filter *metav1.ListOptions) ([]victims.Victim, error) {
+			return custom.EligibleResources(client.Dynamic(), resource, namespace, filter)
+		},
+	}
+}
@@ -0,0 +1,120 @@
+package replicasets
+
+//All these functions require api access specific to the version of the app
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/victims"
+
+	kube "k8s.io/client-go/kubernetes"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EligibleReplicaSets gets all eligible standalone replicasets that opted in (filtered by config.EnabledLabel)
+// ReplicaSets controlled by a Deployment or another workload are skipped,
+// as their controller is the victim
+func EligibleReplicaSets(clientset kube.Interface, namespace string, filter *metav1.ListOptions) (eligVictims []victims.Victim, err error) {
+	enabledVictims, err := clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vic := range enabledVictims.Items {
+		if controller := metav1.GetControllerOf(&vic); controller != nil {
+			glog.V(4).Infof("Skipping eligible %s %s because it is controlled by %s %s", kind, vic.Name, controller.Kind, controller.Name)
+			continue
+		}
+
+		victim, err := New(&vic)
+		if err != nil {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", kind, vic.Name, err.Error())
+			victims.ReportSkipped(kind, vic.Namespace, vic.Name, err.Error())
+			continue
+		}
+
+		eligVictims = append(eligVictims, victim)
+	}
+
+	return
+}
+
+// Returns the replicaset of the victim, looked up once per attack
+func (r *ReplicaSet) get(client victims.VictimKubeClient) (*appsv1.ReplicaSet, error) {
+	return victims.Lookup(client, r, func() (*appsv1.ReplicaSet, error) {
+		return client.Kube().AppsV1().ReplicaSets(r.Namespace()).Get(context.TODO(), r.Name(), metav1.GetOptions{})
+	})
+}
+
+/* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
+
+// IsEnrolled checks if the replicaset is currently enrolled in kube-monkey
+func (r *ReplicaSet) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
+	replicaset, err := r.get(client)
+	if err != nil {
+		return false, err
+	}
+	return r.EnrolledBy(replicaset.GetLabels()), nil
+}
+
+// KillType returns current killtype config label for update
+func (r *ReplicaSet) KillType(client victims.VictimKubeClient) (string, error) {
+	replicaset, err := r.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	return r.KillTypeFrom(replicaset.GetLabels())
+}
+
+// KillValue returns current killvalue config label for update
+func (r *ReplicaSet) KillValue(client victims.VictimKubeClient) (int, error) {
+	replicaset, err := r.get(client)
+	if err != nil {
+		return -1, err
+	}
+
+	return r.KillValueFrom(replicaset.GetLabels())
+}
+
+// Annotate merges the annotations into the replicaset
+func (r *ReplicaSet) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(r, annotations, client.Kube().AppsV1().ReplicaSets(r.Namespace()).Patch)
+}
+
+// Returns the pods of the replicaset, found through its selector and verified
+// through their owner references
+func (r *ReplicaSet) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	replicaset, err := r.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return victims.PodsControlledBy(client, r.Namespace(), replicaset.Spec.Selector, replicaset.UID)
+}
+
+// VerifyRecovery waits for the desired replicas of the replicaset to be ready again
+func (r *ReplicaSet) VerifyRecovery(client victims.VictimKubeClient, attackedAt time.Time, timeout time.Duration) error {
+	return victims.WaitForReadyReplicas(client, r, timeout, r.replicas)
+}
+
+// Returns the desired replicas of the replicaset and its pods
+func (r *ReplicaSet) replicas(client victims.VictimKubeClient) (int32, []corev1.Pod, error) {
+	replicaset, err := r.get(client)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	desired := int32(1)
+	if replicaset.Spec.Replicas != nil {
+		desired = *replicaset.Spec.Replicas
+	}
+	pods, err := victims.PodsControlledBy(client, r.Namespace(), replicaset.Spec.Selector, replicaset.UID)
+	return desired, pods, err
+}
@@ -0,0 +1,111 @@
+package replicasets
+
+import (
+	"context"
+	"testing"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// The kube-monkey labels are read by victims.VictimBase, and tested there
+
+func TestEligibleReplicaSets(t *testing.T) {
+	v1rs := newReplicaSet(NAME, map[string]string{config.MtbfLabelKey: "1"})
+
+	client := fake.NewSimpleClientset(&v1rs)
+	victims, _ := EligibleReplicaSets(client, NAMESPACE, &metav1.ListOptions{})
+
+	assert.Len(t, victims, 1)
+}
+
+func TestIsEnrolled(t *testing.T) {
+	v1rs := newReplicaSet(NAME, map[string]string{
+		config.MtbfLabelKey:    "1",
+		config.EnabledLabelKey: config.EnabledLabelValue,
+	})
+	rs, _ := New(&v1rs)
+
+	b, err := rs.IsEnrolled(victims.NewVictimClient(fake.NewSimpleClientset(&v1rs), nil))
+	assert.NoError(t, err)
+	assert.True(t, b, "Expected replicaset to be enrolled")
+
+	v1rs.Labels[config.EnabledLabelKey] = "x"
+	b, err = rs.IsEnrolled(victims.NewVictimClient(fake.NewSimpleClientset(&v1rs), nil))
+	assert.NoError(t, err)
+	assert.False(t, b, "Expected replicaset to not be enrolled")
+}
+
+func TestAnnotate(t *testing.T) {
+	v1rs := newReplicaSet(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	rs, _ := New(&v1rs)
+	client := fake.NewSimpleClientset(&v1rs)
+
+	err := rs.Annotate(victims.NewVictimClient(client, nil), map[string]string{config.LastKilledAnnotationKey: "2024-01-01T10:00:00Z"})
+	assert.NoError(t, err)
+
+	replicaset, _ := client.AppsV1().ReplicaSets(NAMESPACE).Get(context.TODO(), NAME, metav1.GetOptions{})
+	assert.Equal(t, "2024-01-01T10:00:00Z", replicaset.Annotations[config.LastKilledAnnotationKey])
+}
+
+func newOwnerReference(kind, name string, uid types.UID) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, UID: uid, Controller: &controller}}
+}
+
+func TestEligibleReplicaSetsSkipsControlled(t *testing.T) {
+	v1rs := newReplicaSet(
+		NAME,
+		map[string]string{
+			config.MtbfLabelKey: "1",
+		},
+	)
+	v1rs.OwnerReferences = newOwnerReference("Deployment", "deployment_name", "deployment-uid")
+
+	client := fake.NewSimpleClientset(&v1rs)
+	victims, _ := EligibleReplicaSets(client, NAMESPACE, &metav1.ListOptions{})
+
+	assert.Empty(t, victims, "Expected replicasets controlled by a deployment to be skipped")
+}
+
+func TestPods(t *testing.T) {
+	selector := map[string]string{"app": "web"}
+
+	v1rs := newReplicaSet(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	v1rs.UID = "replicaset-uid"
+	v1rs.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+
+	owned := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            NAME + "-1",
+			Namespace:       NAMESPACE,
+			Labels:          selector,
+			OwnerReferences: newOwnerReference("ReplicaSet", NAME, v1rs.UID),
+		},
+	}
+	// Matches the selector but belongs to another workload
+	foreign := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "other-1",
+			Namespace:       NAMESPACE,
+			Labels:          selector,
+			OwnerReferences: newOwnerReference("ReplicaSet", "other", "other-uid"),
+		},
+	}
+
+	rs, _ := New(&v1rs)
+
+	client := fake.NewSimpleClientset(&v1rs, &owned, &foreign)
+
+	pods, err := rs.Pods(victims.NewVictimClient(client, nil))
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, owned.Name, pods[0].Name)
+}
@@ -0,0 +1,54 @@
+package replicasets
+
+import (
+	"fmt"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+type ReplicaSet struct {
+	*victims.VictimBase
+}
+
+// Kind of the replicasets, i.e. v1.ReplicaSet
+var kind = fmt.Sprintf("%T", appsv1.ReplicaSet{})
+
+// New creates a new instance of ReplicaSet
+func New(rs *appsv1.ReplicaSet) (*ReplicaSet, error) {
+	ident := identifier(rs)
+	mtbf, err := meanTimeBetweenFailures(rs)
+	if err != nil {
+		return nil, err
+	}
+
+	base := victims.New(kind, rs.Name, rs.Namespace, ident, mtbf)
+	base.ReadHistory(rs)
+
+	victim := &ReplicaSet{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
+}
+
+// Returns the value of the label defined by config.IdentLabelKey
+// from the replicaset labels, if any
+// The label is optional, as the pods that belong to this replicaset
+// are found through its selector and verified through owner references
+func identifier(kubekind *appsv1.ReplicaSet) string {
+	return kubekind.Labels[config.IdentLabelKey]
+}
+
+// Read the mean-time-between-failures value defined by the ReplicaSet
+// in the label defined by config.MtbfLabelKey
+func meanTimeBetweenFailures(kubekind *appsv1.ReplicaSet) (time.Duration, error) {
+	mtbf, ok := kubekind.Labels[config.MtbfLabelKey]
+	if !ok {
+		return -1, fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.MtbfLabelKey)
+	}
+
+	return victims.ParseMtbf(mtbf)
+}
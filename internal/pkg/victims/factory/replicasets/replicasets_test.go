@@ -0,0 +1,110 @@
+package replicasets
+
+import (
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/calendar"
+	"kube-monkey/internal/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	IDENTIFIER = "kube-monkey-id"
+	NAME       = "replicaset_name"
+	NAMESPACE  = metav1.NamespaceDefault
+)
+
+func newReplicaSet(name string, labels map[string]string) appsv1.ReplicaSet {
+
+	return appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: NAMESPACE,
+			Labels:    labels,
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	v1rs := newReplicaSet(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "1",
+		},
+	)
+	rs, err := New(&v1rs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.ReplicaSet", rs.Kind())
+	assert.Equal(t, NAME, rs.Name())
+	assert.Equal(t, NAMESPACE, rs.Namespace())
+	assert.Equal(t, IDENTIFIER, rs.Identifier())
+	assert.Equal(t, calendar.Day, rs.Mtbf())
+}
+
+func TestNewDurationMtbf(t *testing.T) {
+	v1rs := newReplicaSet(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "4h",
+		},
+	)
+	rs, err := New(&v1rs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4*time.Hour, rs.Mtbf())
+}
+
+func TestOptionalIdentifier(t *testing.T) {
+	v1rs := newReplicaSet(
+		NAME,
+		map[string]string{
+			config.MtbfLabelKey: "1",
+		},
+	)
+	victim, err := New(&v1rs)
+
+	assert.NoError(t, err, "Expected "+config.IdentLabelKey+" label to be optional")
+	assert.Empty(t, victim.Identifier())
+}
+
+func TestInvalidMtbf(t *testing.T) {
+	v1rs := newReplicaSet(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+		},
+	)
+	_, err := New(&v1rs)
+
+	assert.Errorf(t, err, "Expected an error if "+config.MtbfLabelKey+" label doesn't exist")
+
+	v1rs = newReplicaSet(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "string",
+		},
+	)
+	_, err = New(&v1rs)
+
+	assert.Errorf(t, err, "Expected an error if "+config.MtbfLabelKey+" label can't be converted a Int type")
+
+	v1rs = newReplicaSet(
+		NAME,
+		map[string]string{
+			config.IdentLabelKey: IDENTIFIER,
+			config.MtbfLabelKey:  "0",
+		},
+	)
+	_, err = New(&v1rs)
+
+	assert.Errorf(t, err, "Expected an error if "+config.MtbfLabelKey+" label is lower than 1")
+}
@@ -5,15 +5,16 @@ package statefulsets
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/golang/glog"
 
-	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
 
 	kube "k8s.io/client-go/kubernetes"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,14 +28,8 @@ func EligibleStatefulSets(clientset kube.Interface, namespace string, filter *me
 	for _, vic := range enabledVictims.Items {
 		victim, err := New(&vic)
 		if err != nil {
-			glog.Warningf("Skipping eligible %T %s because of error: %s", vic, vic.Name, err.Error())
-			continue
-		}
-
-		// TODO: After generating whitelisting ns list, this will move to factory.
-		// IsBlacklisted will change to something like IsAllowedNamespace
-		// and will only be used to verify at time of scheduled execution
-		if victim.IsBlacklisted() {
+			glog.Warningf("Skipping eligible %s %s because of error: %s", kind, vic.Name, err.Error())
+			victims.ReportSkipped(kind, vic.Namespace, vic.Name, err.Error())
 			continue
 		}
 
@@ -44,48 +39,121 @@ func EligibleStatefulSets(clientset kube.Interface, namespace string, filter *me
 	return
 }
 
+// Returns the statefulset of the victim, looked up once per attack
+func (ss *StatefulSet) get(client victims.VictimKubeClient) (*appsv1.StatefulSet, error) {
+	return victims.Lookup(client, ss, func() (*appsv1.StatefulSet, error) {
+		return client.Kube().AppsV1().StatefulSets(ss.Namespace()).Get(context.TODO(), ss.Name(), metav1.GetOptions{})
+	})
+}
+
 /* Below methods are used to verify the victim's attributes have not changed at the scheduled time of termination */
 
 // IsEnrolled checks if the statefulset is currently enrolled in kube-monkey
 func (ss *StatefulSet) IsEnrolled(client victims.VictimKubeClient) (bool, error) {
-	statefulset, err := client.Kube().AppsV1().StatefulSets(ss.Namespace()).Get(context.TODO(), ss.Name(), metav1.GetOptions{})
+	statefulset, err := ss.get(client)
 	if err != nil {
 		return false, err
 	}
-	return statefulset.Labels[config.EnabledLabelKey] == config.EnabledLabelValue, nil
+	return ss.EnrolledBy(statefulset.GetLabels()), nil
 }
 
 // KillType returns current killtype config label for update
 func (ss *StatefulSet) KillType(client victims.VictimKubeClient) (string, error) {
-	statefulset, err := client.Kube().AppsV1().StatefulSets(ss.Namespace()).Get(context.TODO(), ss.Name(), metav1.GetOptions{})
+	statefulset, err := ss.get(client)
 	if err != nil {
 		return "", err
 	}
 
-	killType, ok := statefulset.Labels[config.KillTypeLabelKey]
-	if !ok {
-		return "", fmt.Errorf("%s %s does not have %s label", ss.Kind(), ss.Name(), config.KillTypeLabelKey)
-	}
-
-	return killType, nil
+	return ss.KillTypeFrom(statefulset.GetLabels())
 }
 
 // KillValue returns current killvalue config label for update
 func (ss *StatefulSet) KillValue(client victims.VictimKubeClient) (int, error) {
-	statefulset, err := client.Kube().AppsV1().StatefulSets(ss.Namespace()).Get(context.TODO(), ss.Name(), metav1.GetOptions{})
+	statefulset, err := ss.get(client)
 	if err != nil {
 		return -1, err
 	}
 
-	killMode, ok := statefulset.Labels[config.KillValueLabelKey]
-	if !ok {
-		return -1, fmt.Errorf("%s %s does not have %s label", ss.Kind(), ss.Name(), config.KillValueLabelKey)
+	return ss.KillValueFrom(statefulset.GetLabels())
+}
+
+// Annotate merges the annotations into the statefulset
+func (ss *StatefulSet) Annotate(client victims.VictimKubeClient, annotations map[string]string) error {
+	return victims.AnnotateWith(ss, annotations, client.Kube().AppsV1().StatefulSets(ss.Namespace()).Patch)
+}
+
+// Returns the pods of the statefulset with the ordinals selected by OrdinalsAnnotationKey,
+// found through its selector and verified through their owner references
+func (ss *StatefulSet) pods(client victims.VictimKubeClient) ([]corev1.Pod, error) {
+	statefulset, err := ss.get(client)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := victims.PodsControlledBy(client, ss.Namespace(), statefulset.Spec.Selector, statefulset.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	return podsWithOrdinals(pods, statefulset.Name, statefulset.Annotations[OrdinalsAnnotationKey], time.Now())
+}
+
+// UnhealthyReason returns why the statefulset is rolling out or degraded, if it is
+func (ss *StatefulSet) UnhealthyReason(client victims.VictimKubeClient) (string, error) {
+	statefulset, err := ss.get(client)
+	if err != nil {
+		return "", err
+	}
+
+	if !victims.SkipsUnhealthy(statefulset.Labels) {
+		return "", nil
+	}
+
+	if reason := victims.GenerationLagReason(statefulset.Generation, statefulset.Status.ObservedGeneration); reason != "" {
+		return reason, nil
+	}
+
+	replicas := int32(1)
+	if statefulset.Spec.Replicas != nil {
+		replicas = *statefulset.Spec.Replicas
+	}
+
+	// Pods of an OnDelete statefulset are only updated when deleted
+	rollingUpdate := statefulset.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType
+
+	// Only the pods with an ordinal of at least the partition are updated
+	updating := replicas
+	if ru := statefulset.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+		updating = max(0, replicas-*ru.Partition)
 	}
 
-	killModeInt, err := strconv.Atoi(killMode)
-	if err != nil || !(killModeInt > 0) {
-		return -1, fmt.Errorf("Invalid value for label %s: %d", config.KillValueLabelKey, killModeInt)
+	status := statefulset.Status
+	switch {
+	case rollingUpdate && status.UpdatedReplicas < updating:
+		return fmt.Sprintf("rollout in progress: %d of %d replicas updated", status.UpdatedReplicas, updating), nil
+	case status.ReadyReplicas < replicas:
+		return fmt.Sprintf("degraded: %d of %d replicas ready", status.ReadyReplicas, replicas), nil
+	}
+
+	return "", nil
+}
+
+// VerifyRecovery waits for the desired replicas of the statefulset to be ready again
+func (ss *StatefulSet) VerifyRecovery(client victims.VictimKubeClient, attackedAt time.Time, timeout time.Duration) error {
+	return victims.WaitForReadyReplicas(client, ss, timeout, ss.replicas)
+}
+
+// Returns the desired replicas of the statefulset and its pods
+func (ss *StatefulSet) replicas(client victims.VictimKubeClient) (int32, []corev1.Pod, error) {
+	statefulset, err := ss.get(client)
+	if err != nil {
+		return 0, nil, err
 	}
 
-	return killModeInt, nil
+	desired := int32(1)
+	if statefulset.Spec.Replicas != nil {
+		desired = *statefulset.Spec.Replicas
+	}
+	pods, err := ss.pods(client)
+	return desired, pods, err
 }
@@ -7,6 +7,8 @@ import (
 	"kube-monkey/internal/pkg/victims"
 
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 )
@@ -156,3 +158,99 @@ func TestKillValue(t *testing.T) {
 
 	assert.Equalf(t, kill, 1, "Unexpected a kill value, got %d", kill)
 }
+
+func TestPods(t *testing.T) {
+	selector := map[string]string{"app": "db"}
+	controller := true
+
+	v1stfs := newStatefulSet(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	v1stfs.UID = "statefulset-uid"
+	v1stfs.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+
+	newPod := func(name string, owner metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       NAMESPACE,
+				Labels:          selector,
+				OwnerReferences: []metav1.OwnerReference{owner},
+			},
+		}
+	}
+	owned := newPod(NAME+"-0", metav1.OwnerReference{Kind: "StatefulSet", Name: NAME, UID: v1stfs.UID, Controller: &controller})
+	foreign := newPod("other-0", metav1.OwnerReference{Kind: "StatefulSet", Name: "other", UID: "other-uid", Controller: &controller})
+
+	stfs, _ := New(&v1stfs)
+
+	client := fake.NewSimpleClientset(&v1stfs, owned, foreign)
+
+	pods, err := stfs.Pods(victims.NewVictimClient(client, nil))
+
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, owned.Name, pods[0].Name)
+}
+
+func TestUnhealthyReason(t *testing.T) {
+	replicas := int32(3)
+	newStatusStatefulSet := func(strategy appsv1.StatefulSetUpdateStrategyType, status appsv1.StatefulSetStatus) *appsv1.StatefulSet {
+		v1stfs := newStatefulSet(NAME, map[string]string{config.MtbfLabelKey: "1"})
+		v1stfs.Generation = 2
+		v1stfs.Spec.Replicas = &replicas
+		v1stfs.Spec.UpdateStrategy.Type = strategy
+		v1stfs.Status = status
+		return &v1stfs
+	}
+
+	cases := []struct {
+		strategy appsv1.StatefulSetUpdateStrategyType
+		status   appsv1.StatefulSetStatus
+		expected string
+	}{
+		{appsv1.RollingUpdateStatefulSetStrategyType, appsv1.StatefulSetStatus{ObservedGeneration: 2, UpdatedReplicas: 3, ReadyReplicas: 3}, ""},
+		{appsv1.RollingUpdateStatefulSetStrategyType, appsv1.StatefulSetStatus{ObservedGeneration: 1, UpdatedReplicas: 3, ReadyReplicas: 3}, "rollout in progress: generation 2 not observed yet, observed 1"},
+		{appsv1.RollingUpdateStatefulSetStrategyType, appsv1.StatefulSetStatus{ObservedGeneration: 2, UpdatedReplicas: 2, ReadyReplicas: 3}, "rollout in progress: 2 of 3 replicas updated"},
+		{appsv1.OnDeleteStatefulSetStrategyType, appsv1.StatefulSetStatus{ObservedGeneration: 2, UpdatedReplicas: 2, ReadyReplicas: 3}, ""},
+		{appsv1.RollingUpdateStatefulSetStrategyType, appsv1.StatefulSetStatus{ObservedGeneration: 2, UpdatedReplicas: 3, ReadyReplicas: 2}, "degraded: 2 of 3 replicas ready"},
+	}
+
+	for _, c := range cases {
+		v1stfs := newStatusStatefulSet(c.strategy, c.status)
+		stfs, _ := New(v1stfs)
+
+		reason, err := stfs.UnhealthyReason(victims.NewVictimClient(fake.NewSimpleClientset(v1stfs), nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, reason)
+	}
+}
+
+func TestUnhealthyReasonPartition(t *testing.T) {
+	replicas := int32(3)
+	cases := []struct {
+		partition int32
+		updated   int32
+		expected  string
+	}{
+		{2, 1, ""},
+		{2, 0, "rollout in progress: 0 of 1 replicas updated"},
+		{5, 0, ""},
+		{0, 2, "rollout in progress: 2 of 3 replicas updated"},
+	}
+
+	for _, c := range cases {
+		v1stfs := newStatefulSet(NAME, map[string]string{config.MtbfLabelKey: "1"})
+		v1stfs.Spec.Replicas = &replicas
+		v1stfs.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+			Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &c.partition},
+		}
+		v1stfs.Status = appsv1.StatefulSetStatus{UpdatedReplicas: c.updated, ReadyReplicas: replicas}
+		stfs, _ := New(&v1stfs)
+
+		reason, err := stfs.UnhealthyReason(victims.NewVictimClient(fake.NewSimpleClientset(&v1stfs), nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, reason, "partition %d", c.partition)
+	}
+}
@@ -0,0 +1,184 @@
+package statefulsets
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/victims"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// OrdinalsAnnotationKey restricts the candidate pods of a statefulset
+	// by their ordinal. The value is one of OrdinalsHighest,
+	// OrdinalsNonZero or a comma-separated list of ordinals, e.g. "1,2"
+	// An annotation is used as label values cannot hold a list
+	OrdinalsAnnotationKey = "kube-monkey/ordinals"
+
+	// OrdinalsHighest only targets the pod with the highest ordinal
+	OrdinalsHighest = "highest"
+	// OrdinalsNonZero never targets the pod with ordinal 0,
+	// which is often the leader
+	OrdinalsNonZero = "non-zero"
+)
+
+// PollInterval is the interval at which a terminated pod is checked
+// while waiting for it to be Ready again
+var PollInterval = 5 * time.Second
+
+// Filters the pods of the statefulset by the ordinals selection.
+// An empty selection selects all pods. OrdinalsHighest selects the
+// highest ordinal among the pods that can be terminated at t, so that
+// a not ready or protected pod does not leave no pod to terminate
+func podsWithOrdinals(pods []corev1.Pod, name, selection string, t time.Time) ([]corev1.Pod, error) {
+	if selection == "" {
+		return pods, nil
+	}
+
+	ordinals := map[string]int{}
+	for _, pod := range pods {
+		ordinal, err := ordinal(pod, name)
+		if err != nil {
+			glog.Warningf("Skipping pod %s of StatefulSet %s: %v", pod.Name, name, err)
+			continue
+		}
+		ordinals[pod.Name] = ordinal
+	}
+
+	var selected func(int) bool
+	switch selection {
+	case OrdinalsHighest:
+		highest := -1
+		policy := config.PodSelectionPolicy()
+		for _, pod := range pods {
+			if !victims.IsSelectable(pod, policy) || victims.IsProtected(pod, t) {
+				continue
+			}
+			if ordinal, ok := ordinals[pod.Name]; ok && ordinal > highest {
+				highest = ordinal
+			}
+		}
+		selected = func(ordinal int) bool { return ordinal == highest }
+	case OrdinalsNonZero:
+		selected = func(ordinal int) bool { return ordinal != 0 }
+	default:
+		listed, err := parseOrdinals(selection)
+		if err != nil {
+			return nil, err
+		}
+		selected = listed.Has
+	}
+
+	var filtered []corev1.Pod
+	for _, pod := range pods {
+		if ordinal, ok := ordinals[pod.Name]; ok && selected(ordinal) {
+			filtered = append(filtered, pod)
+		}
+	}
+	return filtered, nil
+}
+
+// Parses a comma-separated list of ordinals
+func parseOrdinals(selection string) (sets.Set[int], error) {
+	ordinals := sets.New[int]()
+	for _, entry := range strings.Split(selection, ",") {
+		ordinal, err := strconv.Atoi(strings.TrimSpace(entry))
+		if err != nil || ordinal < 0 {
+			return nil, fmt.Errorf("Invalid value for annotation %s: %s", OrdinalsAnnotationKey, selection)
+		}
+		ordinals.Insert(ordinal)
+	}
+	return ordinals, nil
+}
+
+// Returns the ordinal of a pod of the statefulset, the suffix of its name
+func ordinal(pod corev1.Pod, name string) (int, error) {
+	suffix, ok := strings.CutPrefix(pod.Name, name+"-")
+	if !ok {
+		return -1, fmt.Errorf("pod name does not start with %s-", name)
+	}
+	return strconv.Atoi(suffix)
+}
+
+// DeleteRandomPods terminates killNum random running pods of the statefulset.
+// As the pods of a statefulset have identities, they are terminated one at
+// a time, waiting for each pod to be recreated and Ready before terminating
+// the next one. Returns the number of pods terminated
+func (ss *StatefulSet) DeleteRandomPods(client victims.VictimKubeClient, killNum int) (int, error) {
+	pods, err := ss.RunningPods(client)
+	if err != nil {
+		return 0, err
+	}
+
+	numPods := len(pods)
+	switch {
+	case numPods == 0:
+		return 0, victims.NoPodsError(ss.Kind(), ss.Name())
+	case killNum == 0:
+		return 0, fmt.Errorf("no terminations requested for %s %s", ss.Kind(), ss.Name())
+	case killNum < 0:
+		return 0, fmt.Errorf("cannot request negative terminations %d for %s %s", killNum, ss.Kind(), ss.Name())
+	case numPods < killNum:
+		glog.Warningf("%s %s has only %d currently running pods, but %d terminations requested", ss.Kind(), ss.Name(), numPods, killNum)
+		killNum = numPods
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r.Shuffle(numPods, func(i, j int) { pods[i], pods[j] = pods[j], pods[i] })
+
+	for i, pod := range pods[:killNum] {
+		glog.V(6).Infof("Terminating pod %s for %s %s/%s\n", pod.Name, ss.Kind(), ss.Namespace(), ss.Name())
+
+		if err := ss.DeletePod(client, pod.Name); err != nil {
+			return i, err
+		}
+
+		if i == killNum-1 || config.DryRun() {
+			continue
+		}
+		if err := ss.waitForReady(client, pod.Name, pod.UID, config.RecoveryTimeout()); err != nil {
+			return i + 1, err
+		}
+	}
+
+	return killNum, nil
+}
+
+// DeleteRandomPod terminates a random running pod of the statefulset
+func (ss *StatefulSet) DeleteRandomPod(client victims.VictimKubeClient) error {
+	_, err := ss.DeleteRandomPods(client, 1)
+	return err
+}
+
+// Waits for a terminated pod to be recreated by the statefulset and Ready
+func (ss *StatefulSet) waitForReady(client victims.VictimKubeClient, podName string, terminatedUID types.UID, timeout time.Duration) error {
+	glog.V(6).Infof("Waiting for pod %s of %s %s/%s to be Ready", podName, ss.Kind(), ss.Namespace(), ss.Name())
+
+	err := wait.PollUntilContextTimeout(context.TODO(), PollInterval, timeout, false, func(ctx context.Context) (bool, error) {
+		pod, err := client.Kube().CoreV1().Pods(ss.Namespace()).Get(ctx, podName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return pod.UID != terminatedUID && pod.DeletionTimestamp == nil && victims.IsReady(*pod), nil
+	})
+	if err != nil {
+		return fmt.Errorf("pod %s of %s %s was not Ready again within %s, not terminating more pods: %v", podName, ss.Kind(), ss.Name(), timeout, err)
+	}
+	return nil
+}
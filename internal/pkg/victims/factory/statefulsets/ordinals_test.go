@@ -0,0 +1,199 @@
+package statefulsets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
+	"kube-monkey/internal/pkg/victims"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func newOrdinalsStatefulSet(annotations map[string]string) *appsv1.StatefulSet {
+	v1stfs := newStatefulSet(NAME, map[string]string{config.MtbfLabelKey: "1"})
+	v1stfs.UID = "statefulset-uid"
+	v1stfs.Annotations = annotations
+	v1stfs.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+	return &v1stfs
+}
+
+func newOrdinalPod(v1stfs *appsv1.StatefulSet, ordinal int) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%d", NAME, ordinal),
+			Namespace:       NAMESPACE,
+			UID:             types.UID(fmt.Sprintf("pod-%d", ordinal)),
+			Labels:          v1stfs.Spec.Selector.MatchLabels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: NAME, UID: v1stfs.UID, Controller: &controller}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func newOrdinalsClient(v1stfs *appsv1.StatefulSet, replicas int) *fake.Clientset {
+	objects := []runtime.Object{v1stfs}
+	for i := 0; i < replicas; i++ {
+		objects = append(objects, newOrdinalPod(v1stfs, i))
+	}
+	return fake.NewSimpleClientset(objects...)
+}
+
+func podNames(pods []corev1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPodsWithOrdinals(t *testing.T) {
+	cases := map[string][]string{
+		"":              {NAME + "-0", NAME + "-1", NAME + "-2"},
+		OrdinalsHighest: {NAME + "-2"},
+		OrdinalsNonZero: {NAME + "-1", NAME + "-2"},
+		"0, 2":          {NAME + "-0", NAME + "-2"},
+	}
+
+	for selection, expected := range cases {
+		v1stfs := newOrdinalsStatefulSet(map[string]string{OrdinalsAnnotationKey: selection})
+		stfs, _ := New(v1stfs)
+
+		pods, err := stfs.Pods(victims.NewVictimClient(newOrdinalsClient(v1stfs, 3), nil))
+
+		assert.NoError(t, err)
+		assert.Equalf(t, expected, podNames(pods), "Unexpected pods for ordinals %q", selection)
+	}
+
+	v1stfs := newOrdinalsStatefulSet(map[string]string{OrdinalsAnnotationKey: "leader"})
+	stfs, _ := New(v1stfs)
+
+	_, err := stfs.Pods(victims.NewVictimClient(newOrdinalsClient(v1stfs, 3), nil))
+
+	assert.EqualError(t, err, "Invalid value for annotation "+OrdinalsAnnotationKey+": leader")
+}
+
+func TestPodsWithHighestOrdinal(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	v1stfs := newOrdinalsStatefulSet(map[string]string{OrdinalsAnnotationKey: OrdinalsHighest})
+	stfs, _ := New(v1stfs)
+	notReady := newOrdinalPod(v1stfs, 2)
+	notReady.Status.Conditions = nil
+	protected := newOrdinalPod(v1stfs, 1)
+	protected.Labels = map[string]string{"app": "db", config.ProtectKey: config.ProtectValue}
+	client := fake.NewSimpleClientset(v1stfs, newOrdinalPod(v1stfs, 0), protected, notReady)
+
+	pods, err := stfs.RunningPods(victims.NewVictimClient(client, nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{NAME + "-0"}, podNames(pods), "Expected the highest ordinal among the pods that can be terminated")
+}
+
+func TestDeleteRandomPod(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DryRun, false)
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+
+	v1stfs := newOrdinalsStatefulSet(map[string]string{OrdinalsAnnotationKey: OrdinalsHighest})
+	stfs, _ := New(v1stfs)
+	client := newOrdinalsClient(v1stfs, 3)
+
+	assert.NoError(t, stfs.DeleteRandomPod(victims.NewVictimClient(client, nil)))
+
+	pods, _ := client.CoreV1().Pods(NAMESPACE).List(context.TODO(), metav1.ListOptions{})
+	assert.Equal(t, []string{NAME + "-0", NAME + "-1"}, podNames(pods.Items), "Expected the pod with the highest ordinal to be terminated")
+}
+
+func TestDeleteRandomPodsOneAtATime(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DryRun, false)
+	viper.Set(param.RecoveryTimeoutSec, 1)
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+	pollInterval := PollInterval
+	t.Cleanup(func() { PollInterval = pollInterval })
+	PollInterval = time.Millisecond
+
+	v1stfs := newOrdinalsStatefulSet(nil)
+	stfs, _ := New(v1stfs)
+	client := newOrdinalsClient(v1stfs, 3)
+
+	// Recreate deleted pods Ready with a new UID, as the statefulset controller would
+	var deleted []string
+	client.PrependReactor("delete", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		name := action.(ktesting.DeleteAction).GetName()
+		deleted = append(deleted, name)
+
+		pod, err := client.Tracker().Get(corev1.SchemeGroupVersion.WithResource("pods"), NAMESPACE, name)
+		if err != nil {
+			return true, nil, err
+		}
+		recreated := pod.(*corev1.Pod).DeepCopy()
+		recreated.UID = types.UID(name + "-recreated")
+		return true, nil, client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("pods"), recreated, NAMESPACE)
+	})
+
+	killed, err := stfs.DeleteRandomPods(victims.NewVictimClient(client, nil), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, killed)
+	if assert.Len(t, deleted, 2) {
+		assert.NotEqual(t, deleted[0], deleted[1], "Expected distinct pods to be terminated")
+	}
+}
+
+func TestDeleteRandomPodsNotReadyAgain(t *testing.T) {
+	viper.Reset()
+	config.SetDefaults()
+	viper.Set(param.DryRun, false)
+	viper.Set(param.RecoveryTimeoutSec, 1)
+	defer func() {
+		viper.Reset()
+		config.SetDefaults()
+	}()
+	pollInterval := PollInterval
+	t.Cleanup(func() { PollInterval = pollInterval })
+	PollInterval = time.Millisecond
+
+	v1stfs := newOrdinalsStatefulSet(nil)
+	stfs, _ := New(v1stfs)
+	client := newOrdinalsClient(v1stfs, 3)
+
+	killed, err := stfs.DeleteRandomPods(victims.NewVictimClient(client, nil), 2)
+
+	assert.Error(t, err, "Expected an error if the terminated pod is not recreated")
+	assert.Equal(t, 1, killed)
+
+	pods, _ := client.CoreV1().Pods(NAMESPACE).List(context.TODO(), metav1.ListOptions{})
+	assert.Len(t, pods.Items, 2, "Expected no more terminations after a pod is not Ready again")
+}
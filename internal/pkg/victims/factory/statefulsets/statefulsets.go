@@ -2,7 +2,7 @@ package statefulsets
 
 import (
 	"fmt"
-	"strconv"
+	"time"
 
 	"kube-monkey/internal/pkg/config"
 	"kube-monkey/internal/pkg/victims"
@@ -14,50 +14,41 @@ type StatefulSet struct {
 	*victims.VictimBase
 }
 
+// Kind of the statefulsets, i.e. v1.StatefulSet
+var kind = fmt.Sprintf("%T", corev1.StatefulSet{})
+
 // New creates a new instance of StatefulSet
 func New(ss *corev1.StatefulSet) (*StatefulSet, error) {
-	ident, err := identifier(ss)
-	if err != nil {
-		return nil, err
-	}
+	ident := identifier(ss)
 	mtbf, err := meanTimeBetweenFailures(ss)
 	if err != nil {
 		return nil, err
 	}
-	kind := fmt.Sprintf("%T", *ss)
 
-	return &StatefulSet{VictimBase: victims.New(kind, ss.Name, ss.Namespace, ident, mtbf)}, nil
+	base := victims.New(kind, ss.Name, ss.Namespace, ident, mtbf)
+	base.ReadHistory(ss)
+
+	victim := &StatefulSet{VictimBase: base}
+	base.SetPodFinder(victim.pods)
+
+	return victim, nil
 }
 
 // Returns the value of the label defined by config.IdentLabelKey
-// from the statefulset labels
-// This label should be unique to a statefulset, and is used to
-// identify the pods that belong to this statefulset, as pods
-// inherit labels from the StatefulSet
-func identifier(kubekind *corev1.StatefulSet) (string, error) {
-	identifier, ok := kubekind.Labels[config.IdentLabelKey]
-	if !ok {
-		return "", fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.IdentLabelKey)
-	}
-	return identifier, nil
+// from the statefulset labels, if any
+// The label is optional, as the pods that belong to this statefulset
+// are found through its selector and verified through owner references
+func identifier(kubekind *corev1.StatefulSet) string {
+	return kubekind.Labels[config.IdentLabelKey]
 }
 
 // Read the mean-time-between-failures value defined by the StatefulSet
 // in the label defined by config.MtbfLabelKey
-func meanTimeBetweenFailures(kubekind *corev1.StatefulSet) (int, error) {
+func meanTimeBetweenFailures(kubekind *corev1.StatefulSet) (time.Duration, error) {
 	mtbf, ok := kubekind.Labels[config.MtbfLabelKey]
 	if !ok {
 		return -1, fmt.Errorf("%T %s does not have %s label", kubekind, kubekind.Name, config.MtbfLabelKey)
 	}
 
-	mtbfInt, err := strconv.Atoi(mtbf)
-	if err != nil {
-		return -1, err
-	}
-
-	if !(mtbfInt > 0) {
-		return -1, fmt.Errorf("Invalid value for label %s: %d", config.MtbfLabelKey, mtbfInt)
-	}
-
-	return mtbfInt, nil
+	return victims.ParseMtbf(mtbf)
 }
@@ -0,0 +1,153 @@
+package victims
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReadHistory populates the kill history and the cooldown of the victim
+// from the labels and annotations of the k8s object it was created from
+// An invalid cooldown label is ignored in favor of config.Cooldown
+func (v *VictimBase) ReadHistory(obj metav1.Object) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.killHistory = parseKillHistory(obj.GetAnnotations())
+	v.cooldown = nil
+
+	cooldown, ok := obj.GetLabels()[config.CooldownLabelKey]
+	if !ok {
+		return
+	}
+
+	hours, err := strconv.Atoi(cooldown)
+	if err != nil || hours < 0 {
+		glog.Warningf("Ignoring invalid value for label %s of %s %s: %s. Using the configured cooldown", config.CooldownLabelKey, v.kind, v.name, cooldown)
+		return
+	}
+	d := time.Duration(hours) * time.Hour
+	v.cooldown = &d
+}
+
+// KillHistory returns the times at which the victim was attacked, oldest first
+func (v *VictimBase) KillHistory() []time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	history := make([]time.Time, len(v.killHistory))
+	copy(history, v.killHistory)
+	return history
+}
+
+// LastKilled returns the time the victim was last attacked
+// and false if it has never been attacked
+func (v *VictimBase) LastKilled() (time.Time, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if len(v.killHistory) == 0 {
+		return time.Time{}, false
+	}
+	return v.killHistory[len(v.killHistory)-1], true
+}
+
+// Cooldown returns the per-victim cooldown if set, and the
+// configured cooldown otherwise
+func (v *VictimBase) Cooldown() time.Duration {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.cooldownLocked()
+}
+
+// Returns the cooldown of the victim, with v.mu held
+func (v *VictimBase) cooldownLocked() time.Duration {
+	if v.cooldown != nil {
+		return *v.cooldown
+	}
+	return config.Cooldown()
+}
+
+// IsInCooldown checks if the victim was attacked less than its cooldown before t
+func (v *VictimBase) IsInCooldown(t time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	cooldown := v.cooldownLocked()
+	if cooldown <= 0 || len(v.killHistory) == 0 {
+		return false
+	}
+
+	return t.Sub(v.killHistory[len(v.killHistory)-1]) < cooldown
+}
+
+// RecordKill appends t to the kill history of the victim and returns
+// the annotations that persist the updated history on the k8s object
+func (v *VictimBase) RecordKill(t time.Time) map[string]string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.killHistory = append(v.killHistory, t.UTC().Truncate(time.Second))
+	sort.Slice(v.killHistory, func(i, j int) bool { return v.killHistory[i].Before(v.killHistory[j]) })
+	if len(v.killHistory) > config.KillHistoryLength {
+		v.killHistory = v.killHistory[len(v.killHistory)-config.KillHistoryLength:]
+	}
+
+	return killHistoryAnnotations(v.killHistory)
+}
+
+// Parses the kill history annotation, falling back to the last-killed
+// annotation for objects annotated by hand. Unparsable entries are ignored
+func parseKillHistory(annotations map[string]string) []time.Time {
+	raw, ok := annotations[config.KillHistoryAnnotationKey]
+	if !ok {
+		raw = annotations[config.LastKilledAnnotationKey]
+	}
+
+	var history []time.Time
+	for _, entry := range strings.Split(raw, ",") {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+		history = append(history, t)
+	}
+
+	sort.Slice(history, func(i, j int) bool { return history[i].Before(history[j]) })
+	return history
+}
+
+// Renders the kill history into the annotations written on the victim
+func killHistoryAnnotations(history []time.Time) map[string]string {
+	entries := make([]string, 0, len(history))
+	for _, t := range history {
+		entries = append(entries, t.Format(time.RFC3339))
+	}
+
+	annotations := map[string]string{
+		config.KillHistoryAnnotationKey: strings.Join(entries, ","),
+	}
+	if len(entries) > 0 {
+		annotations[config.LastKilledAnnotationKey] = entries[len(entries)-1]
+	}
+	return annotations
+}
+
+// AnnotationsPatch creates a merge patch setting the given annotations
+func AnnotationsPatch(annotations map[string]string) ([]byte, error) {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	}
+	return json.Marshal(patch)
+}
@@ -1,6 +1,9 @@
 package victims
 
 import (
+	"fmt"
+	"sync"
+
 	"k8s.io/client-go/dynamic"
 	kube "k8s.io/client-go/kubernetes"
 )
@@ -30,3 +33,46 @@ func (vc *victimKubeClient) Kube() kube.Interface {
 func (vc *victimKubeClient) Dynamic() dynamic.Interface {
 	return vc.dynamicClient
 }
+
+// cachingVictimClient caches the victims looked up through Lookup
+type cachingVictimClient struct {
+	VictimKubeClient
+
+	mu      sync.Mutex
+	objects map[string]interface{}
+}
+
+// NewCachingVictimClient wraps the client to cache the victims looked
+// up through Lookup, so the checks made before an attack share a single
+// GET of the victim. It should only be used for the duration of one attack
+func NewCachingVictimClient(client VictimKubeClient) VictimKubeClient {
+	return &cachingVictimClient{
+		VictimKubeClient: client,
+		objects:          map[string]interface{}{},
+	}
+}
+
+// Lookup returns the k8s object of the victim fetched by get, which is
+// only called once per victim if the client was created by NewCachingVictimClient
+func Lookup[T any](client VictimKubeClient, v VictimBaseTemplate, get func() (T, error)) (T, error) {
+	cache, ok := client.(*cachingVictimClient)
+	if !ok {
+		return get()
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", v.Kind(), v.Namespace(), v.Name())
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if obj, ok := cache.objects[key].(T); ok {
+		return obj, nil
+	}
+
+	obj, err := get()
+	if err != nil {
+		return obj, err
+	}
+	cache.objects[key] = obj
+	return obj, nil
+}
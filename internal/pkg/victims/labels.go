@@ -0,0 +1,60 @@
+package victims
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"kube-monkey/internal/pkg/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+/* Below methods read the kube-monkey labels of the current k8s object of a victim */
+
+// EnrolledBy checks if the labels enroll the victim in kube-monkey
+func (v *VictimBase) EnrolledBy(labels map[string]string) bool {
+	return labels[config.EnabledLabelKey] == config.EnabledLabelValue
+}
+
+// KillTypeFrom returns the kill type label of the victim among labels
+func (v *VictimBase) KillTypeFrom(labels map[string]string) (string, error) {
+	killType, ok := labels[config.KillTypeLabelKey]
+	if !ok {
+		return "", fmt.Errorf("%s %s does not have %s label", v.Kind(), v.Name(), config.KillTypeLabelKey)
+	}
+
+	return killType, nil
+}
+
+// KillValueFrom returns the kill value label of the victim among labels,
+// which must be a positive integer
+func (v *VictimBase) KillValueFrom(labels map[string]string) (int, error) {
+	killValue, ok := labels[config.KillValueLabelKey]
+	if !ok {
+		return -1, fmt.Errorf("%s %s does not have %s label", v.Kind(), v.Name(), config.KillValueLabelKey)
+	}
+
+	killValueInt, err := strconv.Atoi(killValue)
+	if err != nil || !(killValueInt > 0) {
+		return -1, fmt.Errorf("Invalid value for label %s: %s", config.KillValueLabelKey, killValue)
+	}
+
+	return killValueInt, nil
+}
+
+// Patcher applies a patch to a k8s object by name, e.g. the Patch
+// method of client.Kube().AppsV1().Deployments(namespace)
+type Patcher[T any] func(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (T, error)
+
+// AnnotateWith merges the annotations into the k8s object of the victim through patch
+func AnnotateWith[T any](v VictimBaseTemplate, annotations map[string]string, patch Patcher[T]) error {
+	data, err := AnnotationsPatch(annotations)
+	if err != nil {
+		return err
+	}
+
+	_, err = patch(context.TODO(), v.Name(), types.MergePatchType, data, metav1.PatchOptions{})
+	return err
+}
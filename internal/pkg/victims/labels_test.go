@@ -0,0 +1,35 @@
+package victims
+
+import (
+	"testing"
+
+	"kube-monkey/internal/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKillModeFromLabels(t *testing.T) {
+	v := newVictimBase()
+	labels := map[string]string{config.EnabledLabelKey: config.EnabledLabelValue}
+
+	assert.True(t, v.EnrolledBy(labels))
+	assert.False(t, v.EnrolledBy(nil))
+
+	_, err := v.KillTypeFrom(labels)
+	assert.EqualError(t, err, v.Kind()+" "+v.Name()+" does not have "+config.KillTypeLabelKey+" label")
+	_, err = v.KillValueFrom(labels)
+	assert.EqualError(t, err, v.Kind()+" "+v.Name()+" does not have "+config.KillValueLabelKey+" label")
+
+	labels[config.KillTypeLabelKey] = config.KillFixedLabelValue
+	labels[config.KillValueLabelKey] = "3"
+	killType, err := v.KillTypeFrom(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, config.KillFixedLabelValue, killType)
+	killValue, err := v.KillValueFrom(labels)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, killValue)
+
+	labels[config.KillValueLabelKey] = "many"
+	_, err = v.KillValueFrom(labels)
+	assert.EqualError(t, err, "Invalid value for label "+config.KillValueLabelKey+": many", "Expected the invalid value in the error")
+}
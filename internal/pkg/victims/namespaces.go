@@ -0,0 +1,43 @@
+package victims
+
+import "sync"
+
+var (
+	namespaceLabelsMu sync.RWMutex
+	namespaceLabels   = map[string]map[string]map[string]string{}
+)
+
+// SetNamespaceLabels replaces the labels of the namespaces of the cluster
+// used to match the label selectors of the namespace whitelist and blacklist
+func SetNamespaceLabels(cluster string, labels map[string]map[string]string) {
+	namespaceLabelsMu.Lock()
+	defer namespaceLabelsMu.Unlock()
+
+	clusterLabels := make(map[string]map[string]string, len(labels))
+	for namespace, l := range labels {
+		clusterLabels[namespace] = l
+	}
+	namespaceLabels[cluster] = clusterLabels
+}
+
+// SetNamespaceLabelsOf replaces the labels of one namespace of the cluster,
+// keeping those of its other namespaces
+func SetNamespaceLabelsOf(cluster, namespace string, labels map[string]string) {
+	namespaceLabelsMu.Lock()
+	defer namespaceLabelsMu.Unlock()
+
+	clusterLabels := make(map[string]map[string]string, len(namespaceLabels[cluster])+1)
+	for ns, l := range namespaceLabels[cluster] {
+		clusterLabels[ns] = l
+	}
+	clusterLabels[namespace] = labels
+	namespaceLabels[cluster] = clusterLabels
+}
+
+// NamespaceLabels returns the labels of the namespace of the cluster set by SetNamespaceLabels
+func NamespaceLabels(cluster, namespace string) map[string]string {
+	namespaceLabelsMu.RLock()
+	defer namespaceLabelsMu.RUnlock()
+
+	return namespaceLabels[cluster][namespace]
+}
@@ -0,0 +1,110 @@
+package victims
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ListOptionsForSelector converts the label selector of a workload into
+// ListOptions. An empty selector is rejected as it would match every pod
+// in the namespace
+func ListOptionsForSelector(selector *metav1.LabelSelector) (*metav1.ListOptions, error) {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	if s.Empty() {
+		return nil, fmt.Errorf("refusing to select pods with an empty selector")
+	}
+	return &metav1.ListOptions{LabelSelector: s.String()}, nil
+}
+
+// PodsControlledBy returns the pods matching the label selector whose
+// controller is one of the owners. Verifying the controller excludes
+// pods of other workloads that happen to match the selector
+func PodsControlledBy(client VictimKubeClient, namespace string, selector *metav1.LabelSelector, owners ...types.UID) ([]corev1.Pod, error) {
+	listOpts, err := ListOptionsForSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	podlist, err := client.Kube().CoreV1().Pods(namespace).List(context.TODO(), *listOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerUIDs := sets.New(owners...)
+	var pods []corev1.Pod
+	for _, pod := range podlist.Items {
+		if controller := metav1.GetControllerOf(&pod); controller != nil && ownerUIDs.Has(controller.UID) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// IsSelectable checks if the pod can be selected for termination under
+// the pod selection policy. Pods that are not running never are, and
+// any policy other than config.PodPolicyRunning excludes terminating pods
+func IsSelectable(pod corev1.Pod, policy string) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+
+	switch policy {
+	case config.PodPolicyRunning, "":
+		return true
+	case config.PodPolicyNotTerminating:
+		return pod.DeletionTimestamp == nil
+	default:
+		return pod.DeletionTimestamp == nil && IsReady(pod)
+	}
+}
+
+// NoPodsError is the error of a victim without pods that can be selected
+// for termination under the pod selection policy
+func NoPodsError(kind, name string) error {
+	return fmt.Errorf("%s %s has no pods to terminate at the moment (pod selection policy: %s)", kind, name, config.PodSelectionPolicy())
+}
+
+// IsReady checks if the Ready condition of the pod is true
+func IsReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// IsProtected checks if the pod is protected from termination at t by the
+// config.ProtectKey label or annotation. The protection expires at the
+// RFC3339 time of the config.ProtectUntilAnnotationKey annotation, if set
+func IsProtected(pod corev1.Pod, t time.Time) bool {
+	if pod.Labels[config.ProtectKey] != config.ProtectValue && pod.Annotations[config.ProtectKey] != config.ProtectValue {
+		return false
+	}
+
+	until, ok := pod.Annotations[config.ProtectUntilAnnotationKey]
+	if !ok {
+		return true
+	}
+
+	expiry, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		// Err on the side of caution for a pod that asked to be protected
+		glog.Warningf("Invalid value for annotation %s of pod %s: %s. Keeping the pod protected", config.ProtectUntilAnnotationKey, pod.Name, until)
+		return true
+	}
+	return t.Before(expiry)
+}
@@ -0,0 +1,66 @@
+package victims
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SkippedVictim is an enrolled k8s object that is not attacked, e.g.
+// because of invalid kube-monkey labels or a blacklisted namespace
+type SkippedVictim struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// SkipError is the error of an attack when the victim was not attacked
+// because of its state at the time of termination, e.g. it opted out
+// or was rolling out
+type SkipError struct {
+	Kind   string
+	Name   string
+	Reason string
+}
+
+func (e *SkipError) Error() string {
+	return fmt.Sprintf("%s %s %s. Skipping", e.Kind, e.Name, e.Reason)
+}
+
+var (
+	skipMu        sync.Mutex
+	skipHandlers  = map[int]func(SkippedVictim){}
+	skipHandlerID int
+)
+
+// ReportSkipped reports the skipped victim to the handlers registered
+// with OnSkip, e.g. to explain a plan of the attacks
+func ReportSkipped(kind, namespace, name, reason string) {
+	skipMu.Lock()
+	handlers := make([]func(SkippedVictim), 0, len(skipHandlers))
+	for _, handler := range skipHandlers {
+		handlers = append(handlers, handler)
+	}
+	skipMu.Unlock()
+
+	skipped := SkippedVictim{Kind: kind, Namespace: namespace, Name: name, Reason: reason}
+	for _, handler := range handlers {
+		handler(skipped)
+	}
+}
+
+// OnSkip registers a handler of the skipped victims, and returns the
+// function unregistering it
+func OnSkip(handler func(SkippedVictim)) func() {
+	skipMu.Lock()
+	defer skipMu.Unlock()
+
+	skipHandlerID++
+	id := skipHandlerID
+	skipHandlers[id] = handler
+	return func() {
+		skipMu.Lock()
+		defer skipMu.Unlock()
+		delete(skipHandlers, id)
+	}
+}
@@ -0,0 +1,66 @@
+package victims
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"kube-monkey/internal/pkg/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// PollInterval is the interval at which the pods of a victim are
+// checked while waiting for it to recover
+var PollInterval = 5 * time.Second
+
+// ReplicaCounter returns the desired replicas of the workload of a victim
+// and its current pods, looked up again on every call
+type ReplicaCounter func(client VictimKubeClient) (desired int32, pods []corev1.Pod, err error)
+
+// SkipsUnhealthy checks if a victim with the labels is skipped while it
+// is rolling out or degraded, which is the default
+func SkipsUnhealthy(labels map[string]string) bool {
+	return labels[config.SkipUnhealthyLabelKey] != "false"
+}
+
+// GenerationLagReason returns a reason if the controller has not observed
+// the latest generation of a workload yet, i.e. a rollout is about to start
+func GenerationLagReason(generation, observedGeneration int64) string {
+	if observedGeneration < generation {
+		return fmt.Sprintf("rollout in progress: generation %d not observed yet, observed %d", generation, observedGeneration)
+	}
+	return ""
+}
+
+// WaitForReadyReplicas waits for the workload of the victim to have as many
+// ready pods as its desired replicas, and returns an error if it did not
+// within timeout. Terminating pods, e.g. the pods just killed, are not counted
+func WaitForReadyReplicas(client VictimKubeClient, v VictimBaseTemplate, timeout time.Duration, replicas ReplicaCounter) error {
+	var desired, ready int32
+	err := wait.PollUntilContextTimeout(context.TODO(), PollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		var pods []corev1.Pod
+		var err error
+		desired, pods, err = replicas(client)
+		if err != nil {
+			return false, err
+		}
+
+		ready = 0
+		for _, pod := range pods {
+			if pod.DeletionTimestamp == nil && IsReady(pod) {
+				ready++
+			}
+		}
+		return ready >= desired, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s did not recover within %s (%d of %d replicas ready): %v", v.Kind(), v.Name(), timeout, ready, desired, err)
+	}
+
+	glog.V(3).Infof("%s %s recovered with %d of %d replicas ready", v.Kind(), v.Name(), ready, desired)
+	return nil
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"strconv"
+	"sync"
 	"time"
 
+	"kube-monkey/internal/pkg/calendar"
 	"kube-monkey/internal/pkg/config"
 
 	"github.com/golang/glog"
@@ -31,16 +34,26 @@ type VictimBaseTemplate interface {
 	Name() string
 	Namespace() string
 	Identifier() string
-	Mtbf() int
+	Mtbf() time.Duration
+	Cluster() string
+	SetCluster(string)
+
+	// Kill history methods
+	KillHistory() []time.Time
+	LastKilled() (time.Time, bool)
+	Cooldown() time.Duration
+	IsInCooldown(time.Time) bool
+	RecordKill(time.Time) map[string]string
 
 	VictimAPICalls
 }
 
 type VictimSpecificAPICalls interface {
 	// Depends on which version i.e. apps/v1 or extensions/v1beta2
-	IsEnrolled(VictimKubeClient) (bool, error) // Get updated enroll status
-	KillType(VictimKubeClient) (string, error) // Get updated kill config type
-	KillValue(VictimKubeClient) (int, error)   // Get updated kill config value
+	IsEnrolled(VictimKubeClient) (bool, error)          // Get updated enroll status
+	KillType(VictimKubeClient) (string, error)          // Get updated kill config type
+	KillValue(VictimKubeClient) (int, error)            // Get updated kill config value
+	Annotate(VictimKubeClient, map[string]string) error // Merge annotations into the victim
 }
 
 type VictimAPICalls interface {
@@ -48,8 +61,8 @@ type VictimAPICalls interface {
 	RunningPods(VictimKubeClient) ([]corev1.Pod, error)
 	Pods(VictimKubeClient) ([]corev1.Pod, error)
 	DeletePod(VictimKubeClient, string) error
-	DeleteRandomPod(VictimKubeClient) error // Deprecated, but faster than DeleteRandomPods for single pod termination
-	DeleteRandomPods(VictimKubeClient, int) error
+	DeleteRandomPod(VictimKubeClient) error              // Deprecated, but faster than DeleteRandomPods for single pod termination
+	DeleteRandomPods(VictimKubeClient, int) (int, error) // Returns the number of pods deleted
 	IsBlacklisted() bool
 	IsWhitelisted() bool
 }
@@ -60,17 +73,74 @@ type VictimKillNumberGenerator interface {
 	KillNumberForFixedPercentage(VictimKubeClient, int) (int, error)
 }
 
+// RecoveryVerifier is implemented by victims that can verify
+// they recovered from an attack, e.g. a Job that still succeeds
+type RecoveryVerifier interface {
+	// VerifyRecovery blocks until the victim recovered from the attack
+	// made at attackedAt, and returns an error if it did not within timeout
+	VerifyRecovery(client VictimKubeClient, attackedAt time.Time, timeout time.Duration) error
+}
+
+// StateRecorder is implemented by recovery verifiers that verify their
+// recovery against their state before the attack, e.g. a new primary
+type StateRecorder interface {
+	// RecordState records the state of the victim before it is attacked
+	RecordState(client VictimKubeClient) error
+}
+
+// KillModeHandler is implemented by victims with kill modes specific
+// to their kind, e.g. a database switchover, in addition to the
+// pod terminations selected by config.KillTypeLabelKey
+type KillModeHandler interface {
+	// HandlesKillMode checks if the kill mode is specific to the victim
+	HandlesKillMode(killType string) bool
+	// Kill executes a kill mode specific to the victim, and returns
+	// the number of pods it terminated. A positive killValue overrides
+	// the value of config.KillValueLabelKey of the victim
+	Kill(client VictimKubeClient, killType string, killValue int) (int, error)
+}
+
+// StatusVerifier is implemented by victims that can report whether
+// their workload is rolling out or degraded, in which case they are
+// not attacked unless their config.SkipUnhealthyLabelKey label is "false"
+type StatusVerifier interface {
+	// UnhealthyReason returns why the victim is rolling out or degraded,
+	// or an empty string if it is healthy or does not skip when unhealthy
+	UnhealthyReason(VictimKubeClient) (string, error)
+}
+
+// ObjectDeleter is implemented by victims whose kills delete the
+// victim object itself, e.g. a standalone Pod, which cannot record
+// its kill history on the deleted object
+type ObjectDeleter interface {
+	// DeletesObject checks if killing the victim deletes its object
+	DeletesObject() bool
+}
+
 type VictimBase struct {
 	kind       string
 	name       string
 	namespace  string
 	identifier string
-	mtbf       int
+	mtbf       time.Duration
+	cluster    string
+
+	mu          sync.Mutex
+	killHistory []time.Time
+	cooldown    *time.Duration
+
+	podFinder PodFinder
 
 	VictimBaseTemplate
 }
 
-func New(kind, name, namespace, identifier string, mtbf int) *VictimBase {
+// PodFinder returns the pods that belong to a victim
+type PodFinder func(VictimKubeClient) ([]corev1.Pod, error)
+
+// MinMtbf is the shortest mean time between failures a victim can request
+const MinMtbf = time.Hour
+
+func New(kind, name, namespace, identifier string, mtbf time.Duration) *VictimBase {
 	return &VictimBase{kind: kind, name: name, namespace: namespace, identifier: identifier, mtbf: mtbf}
 }
 
@@ -90,28 +160,76 @@ func (v *VictimBase) Identifier() string {
 	return v.identifier
 }
 
-func (v *VictimBase) Mtbf() int {
+func (v *VictimBase) Mtbf() time.Duration {
 	return v.mtbf
 }
 
-// RunningPods returns a list of running pods for the victim
+// Cluster returns the name of the cluster target of the victim,
+// which is empty unless config.Clusters is set
+func (v *VictimBase) Cluster() string {
+	return v.cluster
+}
+
+// SetCluster sets the name of the cluster target the victim was found in
+func (v *VictimBase) SetCluster(cluster string) {
+	v.cluster = cluster
+}
+
+// ParseMtbf parses the value of the label defined by config.MtbfLabelKey
+// Plain integers are a number of days, for backwards compatibility,
+// anything else is parsed as a duration, e.g. "4h" or "2w"
+func ParseMtbf(value string) (time.Duration, error) {
+	var mtbf time.Duration
+	if days, err := strconv.Atoi(value); err == nil {
+		mtbf = time.Duration(days) * calendar.Day
+	} else if mtbf, err = calendar.ParseDuration(value); err != nil {
+		return -1, err
+	}
+
+	if mtbf < MinMtbf {
+		return -1, fmt.Errorf("Invalid value for label %s: %s must be at least %s", config.MtbfLabelKey, value, MinMtbf)
+	}
+
+	return mtbf, nil
+}
+
+// RunningPods returns a list of running pods for the victim that can be
+// terminated under config.PodSelectionPolicy. The kill numbers are computed
+// from these pods. Pods protected from termination are excluded
 func (v *VictimBase) RunningPods(client VictimKubeClient) (runningPods []corev1.Pod, err error) {
 	pods, err := v.Pods(client)
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+	policy := config.PodSelectionPolicy()
 	for _, pod := range pods {
-		if pod.Status.Phase == corev1.PodRunning {
-			runningPods = append(runningPods, pod)
+		if !IsSelectable(pod, policy) {
+			continue
+		}
+		if IsProtected(pod, now) {
+			glog.V(4).Infof("Excluding protected pod %s of %s %s/%s", pod.Name, v.kind, v.namespace, v.name)
+			continue
 		}
+		runningPods = append(runningPods, pod)
 	}
 
 	return runningPods, nil
 }
 
+// SetPodFinder overrides how the pods of the victim are found
+// By default pods are found by the identifier label
+func (v *VictimBase) SetPodFinder(finder PodFinder) {
+	v.podFinder = finder
+}
+
 // Pods returns a list of pods under the victim
 func (v *VictimBase) Pods(client VictimKubeClient) ([]corev1.Pod, error) {
+	if v.podFinder != nil {
+		return v.podFinder(client)
+	}
+
 	labelSelector, err := labelFilterForPods(v.identifier)
 	if err != nil {
 		return nil, err
@@ -145,49 +263,50 @@ func (v *VictimBase) GetDeleteOptsForPod() *metav1.DeleteOptions {
 	}
 }
 
-// DeleteRandomPods removes specified number of random pods for the victim
-func (v *VictimBase) DeleteRandomPods(client VictimKubeClient, killNum int) error {
+// DeleteRandomPods removes specified number of distinct random pods for the victim
+// Returns the number of pods deleted, which is less than killNum if the victim
+// has fewer running pods
+func (v *VictimBase) DeleteRandomPods(client VictimKubeClient, killNum int) (int, error) {
 	// Pick a target pod to delete
 	pods, err := v.RunningPods(client)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	numPods := len(pods)
 	switch {
 	case numPods == 0:
-		return fmt.Errorf("%s %s has no running pods at the moment", v.kind, v.name)
+		return 0, NoPodsError(v.kind, v.name)
 	case killNum == 0:
-		return fmt.Errorf("no terminations requested for %s %s", v.kind, v.name)
+		return 0, fmt.Errorf("no terminations requested for %s %s", v.kind, v.name)
 	case numPods < killNum:
 		glog.Warningf("%s %s has only %d currently running pods, but %d terminations requested", v.kind, v.name, numPods, killNum)
+		killNum = numPods
 		fallthrough
 	case numPods == killNum:
 		glog.V(6).Infof("Killing ALL %d running pods for %s %s", numPods, v.kind, v.name)
 	case killNum < 0:
-		return fmt.Errorf("cannot request negative terminations %d for %s %s", killNum, v.kind, v.name)
+		return 0, fmt.Errorf("cannot request negative terminations %d for %s %s", killNum, v.kind, v.name)
 	case numPods > killNum:
 		glog.V(6).Infof("Killing %d running pods for %s %s", killNum, v.kind, v.name)
 	default:
-		return fmt.Errorf("unexpected behavior for terminating %s %s", v.kind, v.name)
+		return 0, fmt.Errorf("unexpected behavior for terminating %s %s", v.kind, v.name)
 	}
 
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r.Shuffle(numPods, func(i, j int) { pods[i], pods[j] = pods[j], pods[i] })
 
-	for i := 0; i < killNum; i++ {
-		victimIndex := r.Intn(numPods)
-		targetPod := pods[victimIndex].Name
+	for i, pod := range pods[:killNum] {
+		glog.V(6).Infof("Terminating pod %s for %s %s/%s\n", pod.Name, v.kind, v.namespace, v.name)
 
-		glog.V(6).Infof("Terminating pod %s for %s %s/%s\n", targetPod, v.kind, v.namespace, v.name)
-
-		err = v.DeletePod(client, targetPod)
+		err = v.DeletePod(client, pod.Name)
 		if err != nil {
-			return err
+			return i, err
 		}
 	}
 
 	// Successful termination
-	return nil
+	return killNum, nil
 }
 
 // Deprecated for DeleteRandomPods(clientset, 1)
@@ -200,7 +319,7 @@ func (v *VictimBase) DeleteRandomPod(client VictimKubeClient) error {
 	}
 
 	if len(pods) == 0 {
-		return fmt.Errorf("%s %s has no running pods at the moment", v.kind, v.name)
+		return NoPodsError(v.kind, v.name)
 	}
 
 	targetPod := RandomPodName(pods)
@@ -210,21 +329,34 @@ func (v *VictimBase) DeleteRandomPod(client VictimKubeClient) error {
 }
 
 // IsBlacklisted checks if this victim is blacklisted
+// The namespace is matched against the names, patterns and label selector
+// of the blacklist of its cluster, using the labels from SetNamespaceLabels
 func (v *VictimBase) IsBlacklisted() bool {
-	if config.BlacklistEnabled() {
-		blacklist := config.BlacklistedNamespaces()
-		return blacklist.Has(v.namespace)
+	if !config.BlacklistEnabledIn(v.cluster) {
+		return false
 	}
-	return false
+	blacklist, err := config.NamespaceBlacklistIn(v.cluster)
+	if err != nil {
+		// Err on the side of caution with an invalid blacklist
+		glog.Errorf("Invalid namespace blacklist: %v. Treating %s as blacklisted", err, v.namespace)
+		return true
+	}
+	return blacklist.Matches(v.namespace, NamespaceLabels(v.cluster, v.namespace))
 }
 
 // IsWhitelisted checks if this victim is whitelisted
+// The namespace is matched against the names, patterns and label selector
+// of the whitelist of its cluster, using the labels from SetNamespaceLabels
 func (v *VictimBase) IsWhitelisted() bool {
-	if config.WhitelistEnabled() {
-		whitelist := config.WhitelistedNamespaces()
-		return whitelist.Has(v.namespace)
+	if !config.WhitelistEnabledIn(v.cluster) {
+		return true
+	}
+	whitelist, err := config.NamespaceWhitelistIn(v.cluster)
+	if err != nil {
+		glog.Errorf("Invalid namespace whitelist: %v. Treating %s as not whitelisted", err, v.namespace)
+		return false
 	}
-	return true
+	return whitelist.Matches(v.namespace, NamespaceLabels(v.cluster, v.namespace))
 }
 
 // Create a label filter to filter only for pods that belong to the this
@@ -2,14 +2,19 @@ package victims
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"kube-monkey/internal/pkg/calendar"
 	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,6 +30,10 @@ const (
 )
 
 func newPod(name string, status corev1.PodPhase) corev1.Pod {
+	ready := corev1.ConditionFalse
+	if status == corev1.PodRunning {
+		ready = corev1.ConditionTrue
+	}
 
 	return corev1.Pod{
 		TypeMeta: metav1.TypeMeta{
@@ -39,7 +48,8 @@ func newPod(name string, status corev1.PodPhase) corev1.Pod {
 			},
 		},
 		Status: corev1.PodStatus{
-			Phase: status,
+			Phase:      status,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
 		},
 	}
 }
@@ -59,7 +69,7 @@ func generateNRunningPods(namePrefix string, n int) []runtime.Object {
 }
 
 func newVictimBase() *VictimBase {
-	return New(KIND, NAME, NAMESPACE, IDENTIFIER, 1)
+	return New(KIND, NAME, NAMESPACE, IDENTIFIER, calendar.Day)
 }
 
 func newVictimClient(client kube.Interface) VictimKubeClient {
@@ -79,7 +89,30 @@ func TestVictimBaseTemplateGetters(t *testing.T) {
 	assert.Equal(t, "name", v.Name())
 	assert.Equal(t, NAMESPACE, v.Namespace())
 	assert.Equal(t, IDENTIFIER, v.Identifier())
-	assert.Equal(t, 1, v.Mtbf())
+	assert.Equal(t, calendar.Day, v.Mtbf())
+}
+
+func TestParseMtbf(t *testing.T) {
+	tcs := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"1", calendar.Day},
+		{"3", 3 * calendar.Day},
+		{"4h", 4 * time.Hour},
+		{"2w", 2 * calendar.Week},
+	}
+
+	for _, tc := range tcs {
+		mtbf, err := ParseMtbf(tc.value)
+		assert.NoError(t, err, tc.value)
+		assert.Equal(t, tc.expected, mtbf, tc.value)
+	}
+
+	for _, value := range []string{"0", "-1", "30m", "string"} {
+		_, err := ParseMtbf(value)
+		assert.Error(t, err, value)
+	}
 }
 
 func TestRunningPods(t *testing.T) {
@@ -137,24 +170,44 @@ func TestDeleteRandomPods(t *testing.T) {
 	podList := getPodList(client).Items
 	assert.Lenf(t, podList, 3, "Expected 3 items in podList, got %d", len(podList))
 
-	err := v.DeleteRandomPods(newVictimClient(client), 0)
+	_, err := v.DeleteRandomPods(newVictimClient(client), 0)
 	assert.NotNil(t, err, "expected err for killNum=0 but got nil")
 
-	err = v.DeleteRandomPods(newVictimClient(client), -1)
+	_, err = v.DeleteRandomPods(newVictimClient(client), -1)
 	assert.NotNil(t, err, "expected err for negative terminations but got nil")
 
-	_ = v.DeleteRandomPods(newVictimClient(client), 1)
+	killed, err := v.DeleteRandomPods(newVictimClient(client), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, killed)
 	podList = getPodList(client).Items
 	assert.Lenf(t, podList, 2, "Expected 2 items in podList, got %d", len(podList))
 
-	_ = v.DeleteRandomPods(newVictimClient(client), 2)
+	killed, err = v.DeleteRandomPods(newVictimClient(client), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, killed, "Expected only the running pods to be counted")
 	podList = getPodList(client).Items
 	assert.Lenf(t, podList, 1, "Expected 1 item in podList, got %d", len(podList))
 	name := podList[0].GetName()
 	assert.Equalf(t, name, "app2", "Expected not running pods not be deleted")
 
-	err = v.DeleteRandomPods(newVictimClient(client), 2)
-	assert.EqualError(t, err, KIND+" "+NAME+" has no running pods at the moment")
+	_, err = v.DeleteRandomPods(newVictimClient(client), 2)
+	assert.EqualError(t, err, KIND+" "+NAME+" has no pods to terminate at the moment (pod selection policy: "+config.PodSelectionPolicy()+")")
+}
+
+func TestDeleteRandomPodsDistinct(t *testing.T) {
+
+	v := newVictimBase()
+	pod1 := newPod("app1", corev1.PodRunning)
+	pod2 := newPod("app2", corev1.PodRunning)
+	pod3 := newPod("app3", corev1.PodRunning)
+
+	client := fake.NewSimpleClientset(&pod1, &pod2, &pod3)
+
+	killed, err := v.DeleteRandomPods(newVictimClient(client), 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, killed)
+	assert.Empty(t, getPodList(client).Items, "Expected every running pod to be terminated once")
 }
 
 func TestKillNumberForMaxPercentage(t *testing.T) {
@@ -295,8 +348,8 @@ func TestDeleteRandomPod(t *testing.T) {
 	podList := getPodList(client).Items
 	assert.Len(t, podList, 1)
 
-	err := v.DeleteRandomPods(newVictimClient(client), 2)
-	assert.EqualError(t, err, KIND+" "+NAME+" has no running pods at the moment")
+	_, err := v.DeleteRandomPods(newVictimClient(client), 2)
+	assert.EqualError(t, err, KIND+" "+NAME+" has no pods to terminate at the moment (pod selection policy: "+config.PodSelectionPolicy()+")")
 }
 
 func TestIsBlacklisted(t *testing.T) {
@@ -308,7 +361,7 @@ func TestIsBlacklisted(t *testing.T) {
 	b := v.IsBlacklisted()
 	assert.False(t, b, "%s namespace should not be blacklisted", NAMESPACE)
 
-	v = New("Pod", "name", metav1.NamespaceSystem, IDENTIFIER, 1)
+	v = New("Pod", "name", metav1.NamespaceSystem, IDENTIFIER, calendar.Day)
 	b = v.IsBlacklisted()
 	assert.True(t, b, "%s namespace should be blacklisted", metav1.NamespaceSystem)
 
@@ -324,6 +377,60 @@ func TestIsWhitelisted(t *testing.T) {
 	assert.True(t, b, "%s namespace should be whitelisted", NAMESPACE)
 }
 
+func TestIsBlacklistedByPatternAndSelector(t *testing.T) {
+	config.SetDefaults()
+	defer SetNamespaceLabels("", nil)
+
+	viper.Set(param.BlacklistedNamespaces, []string{"kube-*"})
+	viper.Set(param.BlacklistedNamespaceSelector, "tier=critical")
+	defer viper.Set(param.BlacklistedNamespaces, []string{metav1.NamespaceSystem})
+	defer viper.Set(param.BlacklistedNamespaceSelector, "")
+	SetNamespaceLabels("", map[string]map[string]string{"payments": {"tier": "critical"}})
+
+	assert.True(t, New("Pod", "name", "kube-public", IDENTIFIER, calendar.Day).IsBlacklisted())
+	assert.True(t, New("Pod", "name", "payments", IDENTIFIER, calendar.Day).IsBlacklisted())
+	assert.False(t, New("Pod", "name", "team-a", IDENTIFIER, calendar.Day).IsBlacklisted())
+
+	viper.Set(param.BlacklistedNamespaces, []string{"/team-(/"})
+	assert.True(t, New("Pod", "name", "team-a", IDENTIFIER, calendar.Day).IsBlacklisted(), "Expected an invalid blacklist to blacklist every namespace")
+}
+
+func TestIsWhitelistedByPatternAndSelector(t *testing.T) {
+	config.SetDefaults()
+	defer SetNamespaceLabels("", nil)
+
+	viper.Set(param.WhitelistedNamespaces, []string{"/^team-[a-z]+$/"})
+	viper.Set(param.WhitelistedNamespaceSelector, "env=staging")
+	defer viper.Set(param.WhitelistedNamespaces, []string{metav1.NamespaceAll})
+	defer viper.Set(param.WhitelistedNamespaceSelector, "")
+	SetNamespaceLabels("", map[string]map[string]string{"checkout": {"env": "staging"}})
+
+	assert.True(t, New("Pod", "name", "team-a", IDENTIFIER, calendar.Day).IsWhitelisted())
+	assert.True(t, New("Pod", "name", "checkout", IDENTIFIER, calendar.Day).IsWhitelisted())
+	assert.False(t, New("Pod", "name", "team-1", IDENTIFIER, calendar.Day).IsWhitelisted())
+}
+
+func TestIsBlacklistedInCluster(t *testing.T) {
+	config.SetDefaults()
+	viper.Set(param.Clusters, []map[string]interface{}{
+		{"name": "staging", "blacklisted_namespaces": []string{""}, "whitelisted_namespaces": []string{"team-*"}},
+	})
+	defer viper.Set(param.Clusters, []map[string]interface{}{})
+
+	v := New("Pod", "name", metav1.NamespaceSystem, IDENTIFIER, calendar.Day)
+	assert.True(t, v.IsBlacklisted())
+	assert.Equal(t, "", v.Cluster())
+
+	v.SetCluster("staging")
+	assert.Equal(t, "staging", v.Cluster())
+	assert.False(t, v.IsBlacklisted(), "Expected the blacklist of the cluster to be used")
+	assert.False(t, v.IsWhitelisted())
+
+	v = New("Pod", "name", "team-a", IDENTIFIER, calendar.Day)
+	v.SetCluster("staging")
+	assert.True(t, v.IsWhitelisted())
+}
+
 func TestRandomPodName(t *testing.T) {
 
 	pod1 := newPod("app1", corev1.PodRunning)
@@ -342,3 +449,231 @@ func TestGetDeleteOptsForPod(t *testing.T) {
 	assert.Equal(t, deleteOpts.GracePeriodSeconds, configuredGracePeriod)
 
 }
+
+func TestReadHistory(t *testing.T) {
+	config.SetDefaults()
+
+	v := newVictimBase()
+	_, ok := v.LastKilled()
+	assert.False(t, ok, "Expected victim without annotations to have no kill history")
+
+	pod := newPod("app", corev1.PodRunning)
+	pod.Annotations = map[string]string{
+		config.KillHistoryAnnotationKey: "2024-01-03T10:00:00Z,2024-01-01T10:00:00Z,invalid",
+	}
+	pod.Labels[config.CooldownLabelKey] = "24"
+
+	v.ReadHistory(&pod)
+	assert.Len(t, v.KillHistory(), 2)
+
+	lastKilled, ok := v.LastKilled()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC), lastKilled)
+	assert.Equal(t, 24*time.Hour, v.Cooldown())
+
+	pod.Labels[config.CooldownLabelKey] = "-1"
+	v.ReadHistory(&pod)
+	assert.Equal(t, config.Cooldown(), v.Cooldown(), "Expected an invalid cooldown to fall back to the configured one")
+}
+
+func TestIsInCooldown(t *testing.T) {
+	config.SetDefaults()
+
+	v := newVictimBase()
+	now := time.Now()
+	assert.False(t, v.IsInCooldown(now), "Expected victim without kill history not to be in cooldown")
+
+	v.RecordKill(now.Add(-2 * time.Hour))
+	assert.False(t, v.IsInCooldown(now), "Expected no cooldown when it is disabled")
+
+	pod := newPod("app", corev1.PodRunning)
+	pod.Annotations = v.RecordKill(now.Add(-2 * time.Hour))
+	pod.Labels[config.CooldownLabelKey] = "3"
+	v.ReadHistory(&pod)
+	assert.True(t, v.IsInCooldown(now), "Expected victim killed 2h ago to be in a 3h cooldown")
+	assert.False(t, v.IsInCooldown(now.Add(time.Hour)), "Expected cooldown to end 3h after the last kill")
+}
+
+func TestRecordKill(t *testing.T) {
+	v := newVictimBase()
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	var annotations map[string]string
+	for i := 0; i < config.KillHistoryLength+2; i++ {
+		annotations = v.RecordKill(start.AddDate(0, 0, i))
+	}
+
+	history := v.KillHistory()
+	assert.Len(t, history, config.KillHistoryLength, "Expected history to be trimmed to the most recent kills")
+	assert.Equal(t, start.AddDate(0, 0, 2), history[0])
+	assert.Equal(t, "2024-01-07T10:00:00Z", annotations[config.LastKilledAnnotationKey])
+	assert.Len(t, strings.Split(annotations[config.KillHistoryAnnotationKey], ","), config.KillHistoryLength)
+}
+
+func TestListOptionsForSelector(t *testing.T) {
+	listOpts, err := ListOptionsForSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "app=web", listOpts.LabelSelector)
+
+	_, err = ListOptionsForSelector(&metav1.LabelSelector{})
+	assert.Error(t, err, "Expected an error for an empty selector")
+}
+
+func TestIsProtected(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	pod := newPod("app", corev1.PodRunning)
+	assert.False(t, IsProtected(pod, now), "Expected a pod without protection to not be protected")
+
+	pod.Labels[config.ProtectKey] = config.ProtectValue
+	assert.True(t, IsProtected(pod, now), "Expected a pod protected by label to be protected")
+
+	pod = newPod("app", corev1.PodRunning)
+	pod.Annotations = map[string]string{config.ProtectKey: config.ProtectValue}
+	assert.True(t, IsProtected(pod, now), "Expected a pod protected by annotation to be protected")
+
+	pod.Annotations[config.ProtectUntilAnnotationKey] = "2024-01-01T12:00:00Z"
+	assert.True(t, IsProtected(pod, now), "Expected a pod to be protected before the expiry")
+	assert.False(t, IsProtected(pod, now.Add(3*time.Hour)), "Expected a pod to not be protected after the expiry")
+
+	pod.Annotations[config.ProtectUntilAnnotationKey] = "tomorrow"
+	assert.True(t, IsProtected(pod, now), "Expected a pod with an invalid expiry to be protected")
+
+	pod = newPod("app", corev1.PodRunning)
+	pod.Annotations = map[string]string{config.ProtectUntilAnnotationKey: "2024-01-01T12:00:00Z"}
+	assert.False(t, IsProtected(pod, now), "Expected an expiry alone to not protect the pod")
+}
+
+func TestRunningPodsExcludesProtected(t *testing.T) {
+	v := newVictimBase()
+	pod1 := newPod("app1", corev1.PodRunning)
+	pod2 := newPod("app2", corev1.PodRunning)
+	pod2.Labels[config.ProtectKey] = config.ProtectValue
+
+	client := fake.NewSimpleClientset(&pod1, &pod2)
+
+	podList, err := v.RunningPods(newVictimClient(client))
+
+	assert.NoError(t, err)
+	assert.Len(t, podList, 1)
+	assert.Equal(t, "app1", podList[0].Name)
+}
+
+func TestIsSelectable(t *testing.T) {
+	running := newPod("app", corev1.PodRunning)
+
+	notReady := newPod("app", corev1.PodRunning)
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+
+	terminating := newPod("app", corev1.PodRunning)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	pending := newPod("app", corev1.PodPending)
+
+	cases := []struct {
+		policy   string
+		expected []bool // running, not ready, terminating, pending
+	}{
+		{config.PodPolicyRunning, []bool{true, true, true, false}},
+		{config.PodPolicyNotTerminating, []bool{true, true, false, false}},
+		{config.PodPolicyReady, []bool{true, false, false, false}},
+	}
+
+	for _, c := range cases {
+		for i, pod := range []corev1.Pod{running, notReady, terminating, pending} {
+			assert.Equalf(t, c.expected[i], IsSelectable(pod, c.policy), "Unexpected selection of pod %d for policy %s", i, c.policy)
+		}
+	}
+}
+
+func TestKillNumberForKillingAllPolicy(t *testing.T) {
+	v := newVictimBase()
+	ready := newPod("app1", corev1.PodRunning)
+	notReady := newPod("app2", corev1.PodRunning)
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+
+	client := newVictimClient(fake.NewSimpleClientset(&ready, &notReady))
+
+	viper.Set(param.PodSelectionPolicy, config.PodPolicyRunning)
+	defer viper.Set(param.PodSelectionPolicy, config.PodPolicyReady)
+
+	killNum, err := v.KillNumberForKillingAll(client)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, killNum)
+
+	viper.Set(param.PodSelectionPolicy, config.PodPolicyReady)
+
+	killNum, err = v.KillNumberForKillingAll(client)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, killNum, "Expected pods that are not Ready to not be counted")
+}
+
+func TestLookup(t *testing.T) {
+	v := newVictimBase()
+
+	lookups := 0
+	get := func() (*corev1.Pod, error) {
+		lookups++
+		pod := newPod("app", corev1.PodRunning)
+		return &pod, nil
+	}
+
+	client := newVictimClient(fake.NewSimpleClientset())
+	_, _ = Lookup(client, v, get)
+	_, _ = Lookup(client, v, get)
+	assert.Equal(t, 2, lookups, "Expected every lookup to GET without a caching client")
+
+	lookups = 0
+	cachingClient := NewCachingVictimClient(client)
+	first, err := Lookup(cachingClient, v, get)
+	assert.NoError(t, err)
+	second, err := Lookup(cachingClient, v, get)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lookups, "Expected a single GET with a caching client")
+	assert.Same(t, first, second)
+
+	other := New("Pod", "other", NAMESPACE, IDENTIFIER, calendar.Day)
+	_, _ = Lookup(cachingClient, other, get)
+	assert.Equal(t, 2, lookups, "Expected victims to be cached separately")
+}
+
+func TestLookupDoesNotCacheErrors(t *testing.T) {
+	v := newVictimBase()
+	cachingClient := NewCachingVictimClient(newVictimClient(fake.NewSimpleClientset()))
+
+	_, err := Lookup(cachingClient, v, func() (*corev1.Pod, error) {
+		return nil, errors.New("not found")
+	})
+	assert.Error(t, err)
+
+	pod, err := Lookup(cachingClient, v, func() (*corev1.Pod, error) {
+		return &corev1.Pod{}, nil
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, pod)
+}
+
+func TestWaitForReadyReplicas(t *testing.T) {
+	pollInterval := PollInterval
+	defer func() { PollInterval = pollInterval }()
+	PollInterval = time.Millisecond
+
+	v := newVictimBase()
+	ready := newPod("app1", corev1.PodRunning)
+	pending := newPod("app2", corev1.PodPending)
+	terminating := newPod("app3", corev1.PodRunning)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	pods := []corev1.Pod{ready, pending, terminating}
+
+	replicas := func(desired int32) ReplicaCounter {
+		return func(VictimKubeClient) (int32, []corev1.Pod, error) {
+			return desired, pods, nil
+		}
+	}
+	client := newVictimClient(fake.NewSimpleClientset())
+
+	assert.NoError(t, WaitForReadyReplicas(client, v, time.Second, replicas(1)))
+
+	err := WaitForReadyReplicas(client, v, 10*time.Millisecond, replicas(2))
+	assert.ErrorContains(t, err, KIND+" "+NAME+" did not recover within 10ms (1 of 2 replicas ready)", "Expected pending and terminating pods not to be counted")
+}
@@ -4,15 +4,26 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/golang/glog"
 
 	"kube-monkey/internal/pkg/config"
+	"kube-monkey/internal/pkg/config/param"
 	"kube-monkey/internal/pkg/kubemonkey"
 )
 
+// Flags overriding the kubernetes config, e.g. to run out of cluster
+var (
+	kubeconfig        = flag.String("kubeconfig", "", "Path of the kubeconfig file. Overrides "+param.Kubeconfig)
+	kubeContext       = flag.String("context", "", "Context of the kubeconfig file. Overrides "+param.KubeContext)
+	impersonateUser   = flag.String("as", "", "User to impersonate. Overrides "+param.ImpersonateUser)
+	impersonateGroups = flag.String("as-group", "", "Comma separated groups to impersonate. Overrides "+param.ImpersonateGroups)
+)
+
 func glogUsage() {
-	fmt.Fprintf(os.Stderr, "usage: example -stderrthreshold=[INFO|WARN|FATAL] -log_dir=[string]\n")
+	fmt.Fprintf(os.Stderr, "usage: example -stderrthreshold=[INFO|WARN|FATAL] -log_dir=[string] [command]\n")
+	fmt.Fprintf(os.Stderr, "commands:\n%s", commandsUsage())
 	flag.PrintDefaults()
 	os.Exit(2)
 }
@@ -38,6 +49,22 @@ func initLogging() {
 	}
 }
 
+func initFlags() {
+	// Flags take precedence over the config file and environment
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "kubeconfig":
+			config.SetFlag(param.Kubeconfig, *kubeconfig)
+		case "context":
+			config.SetFlag(param.KubeContext, *kubeContext)
+		case "as":
+			config.SetFlag(param.ImpersonateUser, *impersonateUser)
+		case "as-group":
+			config.SetFlag(param.ImpersonateGroups, strings.Split(*impersonateGroups, ","))
+		}
+	})
+}
+
 func initConfig() {
 	if err := config.Init(); err != nil {
 		glog.Fatal(err.Error())
@@ -47,6 +74,12 @@ func initConfig() {
 func main() {
 	// Initialize logging
 	initLogging()
+	initFlags()
+
+	// Run the command instead of the daemon, if any
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Arg(0), flag.Args()[1:]))
+	}
 
 	// Initialize configs
 	initConfig()